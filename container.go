@@ -0,0 +1,176 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// rootContainer is the name of the root container of a multitenant container database.
+	rootContainer = "CDB$ROOT"
+
+	// commonUserPrefix is the prefix required of the names of common users, which are
+	// created in the root container and exist in every pluggable database.
+	commonUserPrefix = "C##"
+
+	// defaultCommonUsernameTemplate is used instead of defaultUsernameTemplate when users are
+	// created in the root container and no username_template is configured.
+	defaultCommonUsernameTemplate = `{{ printf "C##V_%s_%s_%s_%s" (.DisplayName | truncate 6) (.RoleName | truncate 6) (random 20) (unix_time) | truncate 30 | uppercase | replace "-" "_" | replace "." "_" }}`
+)
+
+var containerNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]*$`)
+
+// normalizeContainer validates the name of a container and returns it in upper case, which is
+// how Oracle stores the names of containers.
+func normalizeContainer(container string) (string, error) {
+	if len(container) > 128 || !containerNameRegex.MatchString(container) {
+		return "", fmt.Errorf("invalid container name %q", container)
+	}
+	return strings.ToUpper(container), nil
+}
+
+// validateCommonUsername checks that the username can be created in the given container: common users
+// must be created in the root container and local users in a pluggable database.
+func validateCommonUsername(username, container string) error {
+	if container == "" {
+		return nil
+	}
+
+	common := strings.HasPrefix(strings.ToUpper(username), commonUserPrefix)
+	if container == rootContainer && !common {
+		return fmt.Errorf("users created in %s must be common users prefixed with %s, got %q", rootContainer, commonUserPrefix, username)
+	}
+	if container != rootContainer && common {
+		return fmt.Errorf("common user %q can only be created in %s, not in %s", username, rootContainer, container)
+	}
+	return nil
+}
+
+// targetContainer returns the container that statements run in: the configured container, or the container
+// that connection_url lands in on a multitenant database. It is empty if neither is known.
+func targetContainer(container string, server serverInfo) string {
+	if container != "" {
+		return container
+	}
+	return server.container
+}
+
+// withContainer checks out a single connection from the pool, switches it to the given container and calls fn
+// with it. The connection is switched back to its original container before being returned to the pool, or
+// discarded if that fails, so that other operations are unaffected. An empty container leaves the connection in
+// the container `connection_url` lands in.
+func withContainer(ctx context.Context, db *sql.DB, container string, fn func(*sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get connection from pool: %w", err)
+	}
+	defer conn.Close()
+
+	if container == "" {
+		return fn(conn)
+	}
+
+	var original string
+	err = conn.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'CON_NAME') FROM dual`).Scan(&original)
+	if err != nil {
+		return fmt.Errorf("unable to determine current container: %w", err)
+	}
+	if strings.EqualFold(original, container) {
+		return fn(conn)
+	}
+
+	err = setContainer(ctx, conn, container)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := setContainer(ctx, conn, original); err != nil {
+			// Never return a connection in the wrong container to the pool
+			conn.Raw(func(interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+	}()
+
+	return fn(conn)
+}
+
+func setContainer(ctx context.Context, conn *sql.Conn, container string) error {
+	container, err := normalizeContainer(container)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "ALTER SESSION SET CONTAINER = "+container)
+	if err != nil {
+		return fmt.Errorf("unable to switch to container %s: %w", container, err)
+	}
+	return nil
+}
+
+// containerID returns the con_id of the given container, used to restrict session lookups to that container.
+func containerID(ctx context.Context, db *sql.DB, container string) (int, error) {
+	var conID int
+	err := db.QueryRowContext(ctx, `SELECT con_id FROM v$containers WHERE name = :1`, container).Scan(&conID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to find container %s: %w", container, err)
+	}
+	return conID, nil
+}
+
+// userContainersSQL finds the containers a user exists in. A common user exists in every container, but is
+// created in and managed from the root container.
+const userContainersSQL = `SELECT c.name FROM cdb_users u JOIN v$containers c ON c.con_id = u.con_id
+WHERE u.username IN (:1, UPPER(:2)) AND (u.common = 'NO' OR c.name = 'CDB$ROOT')`
+
+// resolveUserContainer sets the container of the statements to the container the user exists in. Only the
+// creation statements are guaranteed to carry `DEFINE container`, so on a multitenant database renewals and
+// revocations look the user up instead of assuming the configured container. A container named by the
+// statements themselves must match it. If the user doesn't exist, the container of the statements is kept.
+//
+// CDB_USERS only lists the users of every container when connected to the root container.
+func (rs *roleStatements) resolveUserContainer(ctx context.Context, db *sql.DB, server serverInfo, username string) error {
+	if !server.cdb {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, userContainersSQL, username, username)
+	if err != nil {
+		return fmt.Errorf("unable to determine the container of user %s: %w", username, classifyError(err))
+	}
+	defer rows.Close()
+
+	var containers []string
+	for rows.Next() {
+		var container string
+		if err := rows.Scan(&container); err != nil {
+			return fmt.Errorf("unable to determine the container of user %s: %w", username, err)
+		}
+		containers = append(containers, strings.ToUpper(container))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("unable to determine the container of user %s: %w", username, classifyError(err))
+	}
+
+	_, defined := rs.defines["container"]
+	switch {
+	case len(containers) == 0:
+		return nil
+	case slices.Contains(containers, rs.container):
+		return nil
+	case defined:
+		return fmt.Errorf("user %s exists in %s, not in container %s", username, strings.Join(containers, ", "), rs.container)
+	case len(containers) > 1:
+		return fmt.Errorf("user %s exists in several containers (%s), set the container with DEFINE container", username, strings.Join(containers, ", "))
+	}
+	rs.container = containers[0]
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestNormalizeContainer(t *testing.T) {
+	type testCase struct {
+		input     string
+		expected  string
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"pdb": {
+			input:    "pdb1",
+			expected: "PDB1",
+		},
+		"root": {
+			input:    "cdb$root",
+			expected: rootContainer,
+		},
+		"injection": {
+			input:     "PDB1; DROP USER SYSTEM",
+			expectErr: true,
+		},
+		"leading digit": {
+			input:     "1PDB",
+			expectErr: true,
+		},
+		"too long": {
+			input:     strings.Repeat("A", 129),
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeContainer(test.input)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("Actual: %q\nExpected: %q", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCommonUsername(t *testing.T) {
+	type testCase struct {
+		username  string
+		container string
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"no container": {
+			username: "V_TEST",
+		},
+		"common user in root": {
+			username:  "C##V_TEST",
+			container: rootContainer,
+		},
+		"local user in root": {
+			username:  "V_TEST",
+			container: rootContainer,
+			expectErr: true,
+		},
+		"local user in pdb": {
+			username:  "V_TEST",
+			container: "PDB1",
+		},
+		"common user in pdb": {
+			username:  "c##v_test",
+			container: "PDB1",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateCommonUsername(test.username, test.container)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeContainer(t *testing.T) {
+	db := new()
+
+	req := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url": "system/oracle@localhost:1521/xe",
+			"container":      "cdb$root",
+		},
+		VerifyConnection: false,
+	}
+	_, err := db.Initialize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if db.container != rootContainer {
+		t.Fatalf("Actual: %q\nExpected: %q", db.container, rootContainer)
+	}
+
+	username, err := db.commonUsernameProducer.Generate(dbplugin.UsernameMetadata{
+		DisplayName: "token-display",
+		RoleName:    "my.role",
+	})
+	if err != nil {
+		t.Fatalf("failed to generate username: %s", err)
+	}
+	if !strings.HasPrefix(username, commonUserPrefix) || len(username) > 30 {
+		t.Fatalf("expected a common username of at most 30 bytes, got %q", username)
+	}
+	if err := validateCommonUsername(username, rootContainer); err != nil {
+		t.Fatalf("generated username is invalid: %s", err)
+	}
+}
+
+func TestOracle_DeleteUserInUserContainer(t *testing.T) {
+	type testCase struct {
+		container  string
+		statements []string
+
+		// userContainers are the containers CDB_USERS lists the user in
+		userContainers []string
+
+		expectedStatements []string
+		expectErr          bool
+	}
+
+	tests := map[string]testCase{
+		"created in other container": {
+			userContainers: []string{"PDB2"},
+			expectedStatements: []string{
+				"ALTER SESSION SET CONTAINER = PDB2",
+				"DROP USER V_TEST",
+				"ALTER SESSION SET CONTAINER = CDB$ROOT",
+			},
+		},
+		"configured container differs": {
+			container:      "PDB1",
+			userContainers: []string{"PDB2"},
+			expectedStatements: []string{
+				"ALTER SESSION SET CONTAINER = PDB2",
+				"DROP USER V_TEST",
+				"ALTER SESSION SET CONTAINER = CDB$ROOT",
+			},
+		},
+		"user not found": {
+			container: "PDB1",
+			expectedStatements: []string{
+				"ALTER SESSION SET CONTAINER = PDB1",
+				"DROP USER V_TEST",
+				"ALTER SESSION SET CONTAINER = CDB$ROOT",
+			},
+		},
+		"several containers": {
+			container:      "PDB1",
+			userContainers: []string{"PDB1", "PDB2"},
+			expectedStatements: []string{
+				"ALTER SESSION SET CONTAINER = PDB1",
+				"DROP USER V_TEST",
+				"ALTER SESSION SET CONTAINER = CDB$ROOT",
+			},
+		},
+		"several containers ambiguous": {
+			userContainers: []string{"PDB1", "PDB2"},
+			expectErr:      true,
+		},
+		"defined container matches": {
+			statements:     []string{"DEFINE container = pdb2", "DROP USER {{username}}"},
+			userContainers: []string{"PDB2"},
+			expectedStatements: []string{
+				"ALTER SESSION SET CONTAINER = PDB2",
+				"DROP USER V_TEST",
+				"ALTER SESSION SET CONTAINER = CDB$ROOT",
+			},
+		},
+		"defined container differs": {
+			statements:     []string{"DEFINE container = PDB1", "DROP USER {{username}}"},
+			userContainers: []string{"PDB2"},
+			expectErr:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"container":                   test.container,
+				"disconnect_sessions":         false,
+				"session_termination_timeout": "0s",
+			})
+			server := fakeServer("19.3.0.0.0", "19.0.0.0.0", "NO", 1, rootContainer)
+			fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+				switch {
+				case strings.Contains(query, "cdb_users"):
+					rows := &fakeRows{columns: []string{"NAME"}}
+					for _, container := range test.userContainers {
+						rows.values = append(rows.values, []driver.Value{container})
+					}
+					return rows, nil
+				case strings.HasPrefix(query, "SELECT SYS_CONTEXT('USERENV', 'CON_NAME')"):
+					return &fakeRows{
+						columns: []string{"CON_NAME"},
+						values:  [][]driver.Value{{rootContainer}},
+					}, nil
+				}
+				return server(query, args)
+			}
+
+			statements := test.statements
+			if statements == nil {
+				statements = []string{"DROP USER {{username}}"}
+			}
+			_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+				Username: "V_TEST",
+				Statements: dbplugin.Statements{
+					Commands: statements,
+				},
+			})
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			actual := fdb.statements()
+			if !reflect.DeepEqual(actual, test.expectedStatements) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expectedStatements)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// supportedCredentialTypes are the credential types NewUser can create users with.
+var supportedCredentialTypes = []dbplugin.CredentialType{
+	dbplugin.CredentialTypePassword,
+	dbplugin.CredentialTypeClientCertificate,
+}
+
+// defaultClientCertificateCreationStatements create a user that authenticates with a client certificate over TCPS.
+// They are used when a client certificate role has no creation statements of its own.
+var defaultClientCertificateCreationStatements = []string{
+	`CREATE USER {{username}} IDENTIFIED EXTERNALLY AS '{{subject}}'`,
+	`GRANT CREATE SESSION TO {{username}}`,
+}
+
+// credentialVariables returns the template variables of the credential of a NewUser request: {{password}} for
+// passwords, and {{subject}} and its alias {{distinguished_name}} for client certificates.
+func credentialVariables(req dbplugin.NewUserRequest) (map[string]string, error) {
+	switch req.CredentialType {
+	case dbplugin.CredentialTypePassword:
+		return map[string]string{
+			"password": req.Password,
+		}, nil
+	case dbplugin.CredentialTypeClientCertificate:
+		subject, err := quoteSubject(req.Subject)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			"subject":            subject,
+			"distinguished_name": subject,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credential type %q", req.CredentialType)
+	}
+}
+
+// quoteSubject escapes the subject DN of a client certificate for use in a string literal, as in
+// `IDENTIFIED EXTERNALLY AS '{{subject}}'`. Single quotes are doubled, as DNs may contain them, e.g. in
+// `O=O'Reilly`.
+func quoteSubject(subject string) (string, error) {
+	if strings.TrimSpace(subject) == "" {
+		return "", fmt.Errorf("client certificate subject must not be empty")
+	}
+	if strings.ContainsAny(subject, "\x00\r\n") {
+		return "", fmt.Errorf("client certificate subject must not contain NUL or line break characters")
+	}
+	return strings.ReplaceAll(subject, "'", "''"), nil
+}
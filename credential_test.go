@@ -0,0 +1,196 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestCredentialVariables(t *testing.T) {
+	type testCase struct {
+		req         dbplugin.NewUserRequest
+		expected    map[string]string
+		expectedErr bool
+	}
+
+	tests := map[string]testCase{
+		"password": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypePassword,
+				Password:       "p4ssw0rd",
+			},
+			expected: map[string]string{
+				"password": "p4ssw0rd",
+			},
+		},
+		"client certificate": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypeClientCertificate,
+				Subject:        "CN=app,O=Example",
+			},
+			expected: map[string]string{
+				"subject":            "CN=app,O=Example",
+				"distinguished_name": "CN=app,O=Example",
+			},
+		},
+		"client certificate with quote": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypeClientCertificate,
+				Subject:        "CN=app,O=O'Reilly",
+			},
+			expected: map[string]string{
+				"subject":            "CN=app,O=O''Reilly",
+				"distinguished_name": "CN=app,O=O''Reilly",
+			},
+		},
+		"empty subject": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypeClientCertificate,
+			},
+			expectedErr: true,
+		},
+		"subject with line break": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypeClientCertificate,
+				Subject:        "CN=app\nO=Example",
+			},
+			expectedErr: true,
+		},
+		"rsa private key": {
+			req: dbplugin.NewUserRequest{
+				CredentialType: dbplugin.CredentialTypeRSAPrivateKey,
+				PublicKey:      []byte("-----BEGIN PUBLIC KEY-----"),
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := credentialVariables(test.req)
+			if test.expectedErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectedErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_NewUserClientCertificate(t *testing.T) {
+	type testCase struct {
+		commands []string
+		expected []string
+	}
+
+	tests := map[string]testCase{
+		"default statements": {
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED EXTERNALLY AS 'CN=app,O=Example'`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+		},
+		"custom statements": {
+			commands: []string{
+				`CREATE USER {{username}} IDENTIFIED EXTERNALLY AS '{{distinguished_name}}' PROFILE cert_users`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED EXTERNALLY AS 'CN=app,O=Example' PROFILE cert_users`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+		},
+		"proxy user": {
+			commands: []string{
+				"DEFINE proxy_target = app_schema",
+			},
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED EXTERNALLY AS 'CN=app,O=Example'`,
+				`GRANT CREATE SESSION TO {{username}}`,
+				`ALTER USER APP_SCHEMA GRANT CONNECT THROUGH {{username}}`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, nil)
+
+			req := dbplugin.NewUserRequest{
+				UsernameConfig: dbplugin.UsernameMetadata{
+					DisplayName: "token",
+					RoleName:    "cert",
+				},
+				Statements: dbplugin.Statements{
+					Commands: test.commands,
+				},
+				CredentialType: dbplugin.CredentialTypeClientCertificate,
+				Subject:        "CN=app,O=Example",
+				Expiration:     time.Now().Add(time.Hour),
+			}
+			resp, err := db.NewUser(context.Background(), req)
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			var expected []string
+			for _, stmt := range test.expected {
+				expected = append(expected, strings.ReplaceAll(stmt, "{{username}}", resp.Username))
+			}
+			actual := fdb.statements()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestOracle_NewUserPasswordRequiresStatements(t *testing.T) {
+	db, _ := newFakeOracle(t, nil)
+
+	req := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "password",
+		},
+		CredentialType: dbplugin.CredentialTypePassword,
+		Password:       "p4ssw0rd",
+		Expiration:     time.Now().Add(time.Hour),
+	}
+	_, err := db.NewUser(context.Background(), req)
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+}
+
+func TestOracle_InitializeSupportedCredentialTypes(t *testing.T) {
+	db := new()
+
+	req := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url": "system/oracle@localhost:1521/xe",
+		},
+		VerifyConnection: false,
+	}
+	resp, err := db.Initialize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	expected := []interface{}{"password", "client_certificate"}
+	actual := resp.Config[dbplugin.SupportedCredentialTypesKey]
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %#v\nExpected: %#v", actual, expected)
+	}
+}
@@ -0,0 +1,611 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDescriptorDepth limits the nesting of connect descriptors, which is at most five levels deep in practice,
+// e.g. DESCRIPTION_LIST, DESCRIPTION, ADDRESS_LIST, ADDRESS, PROTOCOL.
+const maxDescriptorDepth = 16
+
+// descriptorProtocols are the valid values of PROTOCOL in an ADDRESS.
+var descriptorProtocols = map[string]bool{
+	"TCP":       true,
+	"TCPS":      true,
+	"IPC":       true,
+	"BEQ":       true,
+	"SDP":       true,
+	"NMP":       true,
+	"WS":        true,
+	"WSS":       true,
+	"EXADIRECT": true,
+}
+
+// easyConnectParams maps the parameters of Easy Connect Plus to the section of the connect descriptor they are
+// moved to, and the name they have there.
+var easyConnectParams = map[string]struct {
+	section string
+	name    string
+}{
+	"connect_timeout":           {"DESCRIPTION", "CONNECT_TIMEOUT"},
+	"transport_connect_timeout": {"DESCRIPTION", "TRANSPORT_CONNECT_TIMEOUT"},
+	"retry_count":               {"DESCRIPTION", "RETRY_COUNT"},
+	"retry_delay":               {"DESCRIPTION", "RETRY_DELAY"},
+	"expire_time":               {"DESCRIPTION", "EXPIRE_TIME"},
+	"sdu":                       {"DESCRIPTION", "SDU"},
+	"failover":                  {"DESCRIPTION", "FAILOVER"},
+	"load_balance":              {"DESCRIPTION", "LOAD_BALANCE"},
+	"source_route":              {"DESCRIPTION", "SOURCE_ROUTE"},
+	"recv_buf_size":             {"DESCRIPTION", "RECV_BUF_SIZE"},
+	"send_buf_size":             {"DESCRIPTION", "SEND_BUF_SIZE"},
+	"https_proxy":               {"ADDRESS", "HTTPS_PROXY"},
+	"https_proxy_port":          {"ADDRESS", "HTTPS_PROXY_PORT"},
+	"ssl_server_dn_match":       {"SECURITY", "SSL_SERVER_DN_MATCH"},
+	"ssl_server_cert_dn":        {"SECURITY", "SSL_SERVER_CERT_DN"},
+	"wallet_location":           {"SECURITY", "MY_WALLET_DIRECTORY"},
+	"pool_connection_class":     {"CONNECT_DATA", "POOL_CONNECTION_CLASS"},
+	"pool_purity":               {"CONNECT_DATA", "POOL_PURITY"},
+	"pool_boundary":             {"CONNECT_DATA", "POOL_BOUNDARY"},
+	"connection_id_prefix":      {"CONNECT_DATA", "CONNECTION_ID_PREFIX"},
+}
+
+// descriptorNode is a `(NAME=value)` parameter of a connect descriptor, whose value is either a string or a list
+// of parameters.
+type descriptorNode struct {
+	name     string
+	value    string
+	children []*descriptorNode
+}
+
+// String formats the parameter in the normalized form of the plugin: names and keyword values in upper case,
+// without whitespace, and values quoted if they contain special characters.
+func (n *descriptorNode) String() string {
+	var b strings.Builder
+	n.write(&b)
+	return b.String()
+}
+
+func (n *descriptorNode) write(b *strings.Builder) {
+	b.WriteString("(" + n.name + "=")
+	if len(n.children) == 0 {
+		value := n.value
+		switch n.name {
+		case "PROTOCOL", "SERVER":
+			value = strings.ToUpper(value)
+		}
+		if strings.ContainsAny(value, "()=,'#\\ \t\r\n") {
+			value = `"` + value + `"`
+		}
+		b.WriteString(value)
+	}
+	for _, child := range n.children {
+		child.write(b)
+	}
+	b.WriteString(")")
+}
+
+// child returns the first child parameter with the given name.
+func (n *descriptorNode) child(name string) *descriptorNode {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// descriptorParser parses connect descriptors, see parseDescriptor.
+type descriptorParser struct {
+	s   string
+	pos int
+}
+
+// parseDescriptor parses a connect descriptor, so that syntax errors are reported with their position by
+// Initialize rather than as ORA-12154 when connecting. Comments and line breaks, as found in tnsnames.ora, are
+// allowed.
+func parseDescriptor(s string) (*descriptorNode, error) {
+	p := &descriptorParser{s: s}
+	p.skipSpace()
+	n, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at offset %d after the end of the descriptor", p.s[p.pos], p.pos)
+	}
+	return n, nil
+}
+
+func (p *descriptorParser) parseNode(depth int) (*descriptorNode, error) {
+	if depth >= maxDescriptorDepth {
+		return nil, fmt.Errorf("descriptor is nested too deeply at offset %d", p.pos)
+	}
+	err := p.expect('(')
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && isDescriptorNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, fmt.Errorf("expected a parameter name at offset %d", p.pos)
+	}
+	n := &descriptorNode{
+		name: strings.ToUpper(p.s[start:p.pos]),
+	}
+
+	p.skipSpace()
+	err = p.expect('=')
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+
+	if p.peek() == '(' {
+		for p.peek() == '(' {
+			child, err := p.parseNode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+			p.skipSpace()
+		}
+	} else {
+		n.value, err = p.parseValue()
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", n.name, err)
+		}
+	}
+
+	p.skipSpace()
+	err = p.expect(')')
+	if err != nil {
+		return nil, fmt.Errorf("unterminated %s: %w", n.name, err)
+	}
+	return n, nil
+}
+
+func (p *descriptorParser) parseValue() (string, error) {
+	if p.peek() == '"' {
+		end := strings.IndexByte(p.s[p.pos+1:], '"')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote at offset %d", p.pos)
+		}
+		value := p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ')' {
+		switch p.s[p.pos] {
+		case '(', '=', '"':
+			return "", fmt.Errorf("unexpected %q at offset %d", p.s[p.pos], p.pos)
+		}
+		p.pos++
+	}
+	value := strings.TrimSpace(p.s[start:p.pos])
+	if value == "" {
+		return "", fmt.Errorf("empty value at offset %d", start)
+	}
+	return value, nil
+}
+
+func (p *descriptorParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *descriptorParser) expect(c byte) error {
+	if p.pos >= len(p.s) {
+		return fmt.Errorf("expected %q at the end of the descriptor", c)
+	}
+	if p.s[p.pos] != c {
+		return fmt.Errorf("expected %q at offset %d, got %q", c, p.pos, p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// skipSpace skips whitespace and `#` comments.
+func (p *descriptorParser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isDescriptorNameChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// validateDescriptor checks the structure of a parsed connect descriptor: each DESCRIPTION needs at least one
+// ADDRESS and a CONNECT_DATA naming the service, and each ADDRESS a known protocol, with a host and port for TCP.
+// Unknown parameters are allowed, but misspelled required ones are caught as missing.
+func validateDescriptor(n *descriptorNode) error {
+	switch n.name {
+	case "DESCRIPTION":
+		return validateDescription(n)
+	case "DESCRIPTION_LIST":
+		descriptions := 0
+		for _, child := range n.children {
+			switch {
+			case child.name == "DESCRIPTION":
+				err := validateDescription(child)
+				if err != nil {
+					return err
+				}
+				descriptions++
+			case len(child.children) > 0:
+				return fmt.Errorf("unexpected %s in DESCRIPTION_LIST", child.name)
+			}
+		}
+		if descriptions == 0 {
+			return fmt.Errorf("DESCRIPTION_LIST has no DESCRIPTION")
+		}
+		return nil
+	default:
+		return fmt.Errorf("descriptor must start with DESCRIPTION or DESCRIPTION_LIST, got %s", n.name)
+	}
+}
+
+func validateDescription(n *descriptorNode) error {
+	addresses := 0
+	connectData := 0
+	for _, child := range n.children {
+		switch child.name {
+		case "ADDRESS":
+			err := validateAddress(child)
+			if err != nil {
+				return err
+			}
+			addresses++
+		case "ADDRESS_LIST":
+			for _, address := range child.children {
+				switch {
+				case address.name == "ADDRESS":
+					err := validateAddress(address)
+					if err != nil {
+						return err
+					}
+					addresses++
+				case len(address.children) > 0:
+					return fmt.Errorf("unexpected %s in ADDRESS_LIST", address.name)
+				}
+			}
+		case "CONNECT_DATA":
+			err := validateConnectData(child)
+			if err != nil {
+				return err
+			}
+			connectData++
+		case "SECURITY":
+		default:
+			if len(child.children) > 0 {
+				return fmt.Errorf("unexpected %s in DESCRIPTION", child.name)
+			}
+		}
+	}
+
+	if addresses == 0 {
+		return fmt.Errorf("DESCRIPTION has no ADDRESS")
+	}
+	if connectData != 1 {
+		return fmt.Errorf("DESCRIPTION must have exactly one CONNECT_DATA, got %d", connectData)
+	}
+	return nil
+}
+
+func validateAddress(n *descriptorNode) error {
+	params, err := descriptorParams(n)
+	if err != nil {
+		return err
+	}
+
+	protocol := strings.ToUpper(params["PROTOCOL"])
+	if protocol == "" {
+		return fmt.Errorf("ADDRESS has no PROTOCOL")
+	}
+	if !descriptorProtocols[protocol] {
+		return fmt.Errorf("invalid PROTOCOL %q in ADDRESS", params["PROTOCOL"])
+	}
+	if protocol != "TCP" && protocol != "TCPS" {
+		return nil
+	}
+
+	if params["HOST"] == "" {
+		return fmt.Errorf("ADDRESS has no HOST")
+	}
+	return validatePort(params["PORT"])
+}
+
+func validateConnectData(n *descriptorNode) error {
+	params, err := descriptorParams(n)
+	if err != nil {
+		return err
+	}
+
+	if (params["SERVICE_NAME"] == "") == (params["SID"] == "") {
+		return fmt.Errorf("CONNECT_DATA must have exactly one of SERVICE_NAME or SID")
+	}
+	if server := params["SERVER"]; server != "" && !serverTypes[strings.ToLower(server)] {
+		return fmt.Errorf("invalid SERVER %q in CONNECT_DATA", server)
+	}
+	return nil
+}
+
+// descriptorParams returns the string parameters of a descriptor section, which must not be repeated.
+func descriptorParams(n *descriptorNode) (map[string]string, error) {
+	params := map[string]string{}
+	for _, child := range n.children {
+		if len(child.children) > 0 {
+			continue
+		}
+		if _, ok := params[child.name]; ok {
+			return nil, fmt.Errorf("%s is repeated in %s", child.name, n.name)
+		}
+		params[child.name] = child.value
+	}
+	return params, nil
+}
+
+func validatePort(port string) error {
+	if port == "" {
+		return fmt.Errorf("ADDRESS has no PORT")
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid PORT %q: must be between 1 and 65535", port)
+	}
+	return nil
+}
+
+// isEasyConnectPlus reports whether an Easy Connect string uses syntax that only Easy Connect Plus supports, or
+// has Easy Connect Plus parameters, and has to be converted into a connect descriptor for the drivers.
+func isEasyConnectPlus(connect string, params map[string]string) bool {
+	if len(params) > 0 || strings.ContainsAny(connect, ",;") {
+		return true
+	}
+	scheme, _, ok := strings.Cut(connect, "://")
+	return ok && scheme != ""
+}
+
+// parseEasyConnectPlus converts an Easy Connect Plus string into a connect descriptor:
+//
+//	[[protocol:]//]host1[,host2][:port1][,host3:port3][;host4...][/[service_name][:server][/instance_name]]
+//
+// Hosts without a port share the port that follows them, as in Oracle's own Easy Connect adapter. The
+// parameters, taken from the query of the connection URL, are moved into the sections of the descriptor they
+// belong to.
+func parseEasyConnectPlus(connect string, params map[string]string) (*descriptorNode, error) {
+	protocol := "TCP"
+	if scheme, rest, ok := strings.Cut(connect, "://"); ok && scheme != "" {
+		protocol = strings.ToUpper(scheme)
+		if protocol != "TCP" && protocol != "TCPS" {
+			return nil, fmt.Errorf("invalid protocol %q: must be tcp or tcps", scheme)
+		}
+		connect = rest
+	}
+	connect = strings.TrimPrefix(connect, "//")
+
+	addressPart, path := connect, ""
+	if i := strings.IndexByte(connect, '/'); i >= 0 {
+		addressPart, path = connect[:i], connect[i+1:]
+	}
+
+	var addressLists [][]*descriptorNode
+	for _, list := range strings.Split(addressPart, ";") {
+		addresses, err := parseEasyConnectAddresses(list, protocol)
+		if err != nil {
+			return nil, err
+		}
+		addressLists = append(addressLists, addresses)
+	}
+
+	service, instance, _ := strings.Cut(path, "/")
+	serviceName, server, _ := strings.Cut(service, ":")
+	connectData := &descriptorNode{name: "CONNECT_DATA"}
+	if serviceName == "" {
+		return nil, fmt.Errorf("Easy Connect string has no service name")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"SERVICE_NAME", serviceName},
+		{"SERVER", server},
+		{"INSTANCE_NAME", instance},
+	} {
+		if field.value == "" {
+			continue
+		}
+		err := validateConnectionField(strings.ToLower(field.name), field.value)
+		if err != nil {
+			return nil, err
+		}
+		connectData.children = append(connectData.children, &descriptorNode{name: field.name, value: field.value})
+	}
+
+	description := &descriptorNode{name: "DESCRIPTION"}
+	security := &descriptorNode{name: "SECURITY"}
+	addressParams := []*descriptorNode{}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		param, ok := easyConnectParams[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported Easy Connect parameter %q", name)
+		}
+		value := params[name]
+		if value == "" || strings.ContainsAny(value, `()"`) {
+			return nil, fmt.Errorf("invalid value %q of Easy Connect parameter %q", value, name)
+		}
+		node := &descriptorNode{name: param.name, value: value}
+		switch param.section {
+		case "DESCRIPTION":
+			description.children = append(description.children, node)
+		case "ADDRESS":
+			addressParams = append(addressParams, node)
+		case "SECURITY":
+			security.children = append(security.children, node)
+		case "CONNECT_DATA":
+			connectData.children = append(connectData.children, node)
+		}
+	}
+
+	// Multiple hosts are load balanced by default, like with Oracle's own Easy Connect adapter
+	if len(addressLists) > 1 || len(addressLists[0]) > 1 {
+		if _, ok := params["load_balance"]; !ok {
+			description.children = append(description.children, &descriptorNode{name: "LOAD_BALANCE", value: "ON"})
+		}
+	}
+
+	for _, addresses := range addressLists {
+		for _, address := range addresses {
+			address.children = append(address.children, addressParams...)
+		}
+		if len(addressLists) == 1 {
+			description.children = append(description.children, addresses...)
+			continue
+		}
+		description.children = append(description.children, &descriptorNode{name: "ADDRESS_LIST", children: addresses})
+	}
+	description.children = append(description.children, connectData)
+	if len(security.children) > 0 {
+		description.children = append(description.children, security)
+	}
+
+	err := validateDescriptor(description)
+	if err != nil {
+		return nil, err
+	}
+	return description, nil
+}
+
+// parseEasyConnectAddresses parses a comma separated list of hosts with optional ports.
+func parseEasyConnectAddresses(list, protocol string) ([]*descriptorNode, error) {
+	var addresses []*descriptorNode
+	pending := 0
+	for _, hostPort := range strings.Split(list, ",") {
+		hostPort = strings.TrimSpace(hostPort)
+		if hostPort == "" {
+			return nil, fmt.Errorf("empty host in Easy Connect string")
+		}
+
+		host, port := hostPort, ""
+		if h, p, err := net.SplitHostPort(hostPort); err == nil {
+			host, port = h, p
+		}
+		host = strings.Trim(host, "[]")
+		if net.ParseIP(host) == nil {
+			err := validateConnectionField("host", host)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		addresses = append(addresses, &descriptorNode{
+			name: "ADDRESS",
+			children: []*descriptorNode{
+				{name: "PROTOCOL", value: protocol},
+				{name: "HOST", value: host},
+			},
+		})
+		pending++
+
+		if port == "" {
+			continue
+		}
+		err := validatePort(port)
+		if err != nil {
+			return nil, err
+		}
+		// The port applies to the hosts listed before it without one
+		for _, address := range addresses[len(addresses)-pending:] {
+			address.children = append(address.children, &descriptorNode{name: "PORT", value: port})
+		}
+		pending = 0
+	}
+	for _, address := range addresses[len(addresses)-pending:] {
+		address.children = append(address.children, &descriptorNode{name: "PORT", value: defaultPort})
+	}
+	return addresses, nil
+}
+
+// normalizeDescriptor parses, validates and normalizes a connect descriptor.
+func normalizeDescriptor(descriptor string) (string, error) {
+	n, err := parseDescriptor(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("invalid connect descriptor: %w", err)
+	}
+	err = validateDescriptor(n)
+	if err != nil {
+		return "", fmt.Errorf("invalid connect descriptor: %w", err)
+	}
+	return n.String(), nil
+}
+
+// withSecurityParams returns the connect descriptor with the parameters set in the SECURITY section of each of its
+// descriptions, replacing parameters of the same name.
+func withSecurityParams(descriptor string, params map[string]string) (string, error) {
+	n, err := parseDescriptor(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("invalid connect descriptor: %w", err)
+	}
+
+	descriptions := []*descriptorNode{n}
+	if n.name == "DESCRIPTION_LIST" {
+		descriptions = nil
+		for _, child := range n.children {
+			if child.name == "DESCRIPTION" {
+				descriptions = append(descriptions, child)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, description := range descriptions {
+		security := description.child("SECURITY")
+		if security == nil {
+			security = &descriptorNode{name: "SECURITY"}
+			description.children = append(description.children, security)
+		}
+		children := security.children[:0]
+		for _, child := range security.children {
+			if _, ok := params[child.name]; !ok {
+				children = append(children, child)
+			}
+		}
+		for _, name := range names {
+			children = append(children, &descriptorNode{name: name, value: params[name]})
+		}
+		security.children = children
+	}
+	return n.String(), nil
+}
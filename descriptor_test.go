@@ -0,0 +1,314 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestNormalizeDescriptor(t *testing.T) {
+	type testCase struct {
+		input     string
+		expected  string
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"normalized": {
+			input:    "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expected: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+		},
+		"whitespace, case and comments": {
+			input: `
+				(description =
+				  # primary
+				  (address = (protocol = tcp) (host = db) (port = 1521))
+				  (connect_data = (service_name = orcl) (server = dedicated))
+				)`,
+			expected: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)(SERVER=DEDICATED)))",
+		},
+		"failover list": {
+			input:    "(DESCRIPTION_LIST=(FAILOVER=on)(DESCRIPTION=(ADDRESS_LIST=(LOAD_BALANCE=on)(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521)))(CONNECT_DATA=(SERVICE_NAME=orcl)))(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=dr)(PORT=1521))(CONNECT_DATA=(SID=orcl))))",
+			expected: "(DESCRIPTION_LIST=(FAILOVER=on)(DESCRIPTION=(ADDRESS_LIST=(LOAD_BALANCE=on)(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521)))(CONNECT_DATA=(SERVICE_NAME=orcl)))(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=dr)(PORT=1521))(CONNECT_DATA=(SID=orcl))))",
+		},
+		"quoted value": {
+			input:    `(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=orcl))(SECURITY=(SSL_SERVER_CERT_DN="CN=db, O=Example")))`,
+			expected: `(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=orcl))(SECURITY=(SSL_SERVER_CERT_DN="CN=db, O=Example")))`,
+		},
+		"unknown parameters": {
+			input:    "(DESCRIPTION=(RETRY_COUNT=3)(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)(CID=(PROGRAM=vault))))",
+			expected: "(DESCRIPTION=(RETRY_COUNT=3)(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)(CID=(PROGRAM=vault))))",
+		},
+		"ipc address": {
+			input:    "(DESCRIPTION=(ADDRESS=(PROTOCOL=IPC)(KEY=orcl))(CONNECT_DATA=(SID=orcl)))",
+			expected: "(DESCRIPTION=(ADDRESS=(PROTOCOL=IPC)(KEY=orcl))(CONNECT_DATA=(SID=orcl)))",
+		},
+		"unbalanced": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl))",
+			expectErr: true,
+		},
+		"text after descriptor": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))x",
+			expectErr: true,
+		},
+		"unterminated quote": {
+			input:     `(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST="db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))`,
+			expectErr: true,
+		},
+		"empty value": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"not a description": {
+			input:     "(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))",
+			expectErr: true,
+		},
+		"misspelled address": {
+			input:     "(DESCRIPTION=(ADRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"misspelled service name": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAM=orcl)))",
+			expectErr: true,
+		},
+		"missing connect data": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521)))",
+			expectErr: true,
+		},
+		"missing port": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"invalid port": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=15210000))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"invalid protocol": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=UDP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"invalid server": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)(SERVER=exclusive)))",
+			expectErr: true,
+		},
+		"repeated host": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(HOST=db2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expectErr: true,
+		},
+		"service name and sid": {
+			input:     "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)(SID=orcl)))",
+			expectErr: true,
+		},
+		"nested description": {
+			input:     "(DESCRIPTION=(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl))))",
+			expectErr: true,
+		},
+		"too deep": {
+			input:     strings.Repeat("(A=", 20) + "x" + strings.Repeat(")", 20),
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeDescriptor(test.input)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeOCI8DSN(t *testing.T) {
+	type testCase struct {
+		input     string
+		expected  string
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"easy connect": {
+			input:    "{{username}}/{{password}}@db:1521/orcl?isolation=SERIALIZABLE",
+			expected: "{{username}}/{{password}}@db:1521/orcl?isolation=SERIALIZABLE",
+		},
+		"tns alias": {
+			input:    "{{username}}/{{password}}@ORCL",
+			expected: "{{username}}/{{password}}@ORCL",
+		},
+		"connect descriptor": {
+			input:    "{{username}}/{{password}}@(description=(address=(protocol=tcp)(host=db)(port=1521))(connect_data=(service_name=orcl)))?as=sysdba",
+			expected: "{{username}}/{{password}}@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))?as=sysdba",
+		},
+		"hosts sharing a port": {
+			input:    "{{username}}/{{password}}@db1,db2:1522,db3/orcl",
+			expected: "{{username}}/{{password}}@(DESCRIPTION=(LOAD_BALANCE=ON)(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1522))(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1522))(ADDRESS=(PROTOCOL=TCP)(HOST=db3)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+		},
+		"address lists": {
+			input:    "{{username}}/{{password}}@db1:1521,[::1]:1522;dr:1521/orcl:dedicated/inst1?failover=on&load_balance=off",
+			expected: "{{username}}/{{password}}@(DESCRIPTION=(FAILOVER=on)(LOAD_BALANCE=off)(ADDRESS_LIST=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(ADDRESS=(PROTOCOL=TCP)(HOST=::1)(PORT=1522)))(ADDRESS_LIST=(ADDRESS=(PROTOCOL=TCP)(HOST=dr)(PORT=1521)))(CONNECT_DATA=(SERVICE_NAME=orcl)(SERVER=DEDICATED)(INSTANCE_NAME=inst1)))",
+		},
+		"protocol and parameters": {
+			input:    "{{username}}/{{password}}@tcps://db:2484/orcl?ssl_server_cert_dn=CN%3Ddb%2CO%3DExample&retry_count=3&as=sysdba",
+			expected: `{{username}}/{{password}}@(DESCRIPTION=(RETRY_COUNT=3)(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=orcl))(SECURITY=(SSL_SERVER_CERT_DN="CN=db,O=Example")))?as=sysdba`,
+		},
+		"invalid descriptor": {
+			input:     "{{username}}/{{password}}@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE=orcl)))",
+			expectErr: true,
+		},
+		"descriptor with easy connect parameters": {
+			input:     "{{username}}/{{password}}@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))?retry_count=3",
+			expectErr: true,
+		},
+		"invalid protocol": {
+			input:     "{{username}}/{{password}}@udp://db:1521/orcl",
+			expectErr: true,
+		},
+		"missing service": {
+			input:     "{{username}}/{{password}}@db1,db2:1521",
+			expectErr: true,
+		},
+		"empty host": {
+			input:     "{{username}}/{{password}}@db1,,db2:1521/orcl",
+			expectErr: true,
+		},
+		"invalid port": {
+			input:     "{{username}}/{{password}}@db1:0,db2:1521/orcl",
+			expectErr: true,
+		},
+		"invalid parameter value": {
+			input:     "{{username}}/{{password}}@db1,db2/orcl?retry_count=" + url.QueryEscape("3)(HOST=evil"),
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeOCI8DSN(test.input)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestRedactConnectionURL(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"placeholders": {
+			input:    "{{username}}/{{password}}@db:1521/orcl",
+			expected: "{{username}}/{{password}}@db:1521/orcl",
+		},
+		"oci8 password": {
+			input:    "system/p@ss/w0rd@db:1521/orcl",
+			expected: "system/[password]@db:1521/orcl",
+		},
+		"oci8 colon": {
+			input:    "system:secret@db:1521/orcl",
+			expected: "system:[password]@db:1521/orcl",
+		},
+		"go-ora password": {
+			input:    "oracle://system:secret@db:1521/orcl?SSL=true",
+			expected: "oracle://system:[password]@db:1521/orcl?SSL=true",
+		},
+		"no password": {
+			input:    "system@db:1521/orcl",
+			expected: "system@db:1521/orcl",
+		},
+		"no credentials": {
+			input:    "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+			expected: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := redactConnectionURL(test.input)
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeParsedConnectionURL(t *testing.T) {
+	type testCase struct {
+		connURL   string
+		expected  string
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"connect descriptor": {
+			connURL:  "system/r00tPassw0rd@(description = (address = (protocol = tcp)(host = db)(port = 1521)) (connect_data = (service_name = orcl)))",
+			expected: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+		},
+		"easy connect plus": {
+			connURL:  "system/r00tPassw0rd@db1,db2/orcl",
+			expected: "(DESCRIPTION=(LOAD_BALANCE=ON)(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))",
+		},
+		"invalid descriptor": {
+			connURL:   "system/r00tPassw0rd@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl))",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := new()
+			defer db.Close()
+
+			req := dbplugin.InitializeRequest{
+				Config: map[string]interface{}{
+					"connection_url": test.connURL,
+				},
+				VerifyConnection: false,
+			}
+			resp, err := db.Initialize(context.Background(), req)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if test.expectErr {
+				return
+			}
+
+			if resp.Config["connection_url"] != test.connURL {
+				t.Fatalf("connection_url should be returned as written, got %v", resp.Config["connection_url"])
+			}
+			if _, ok := req.Config[parsedConnectionURLKey]; ok || len(req.Config) != 1 {
+				t.Fatalf("expected the config of the request to be left untouched, got %v", req.Config)
+			}
+			parsed, _ := resp.Config[parsedConnectionURLKey].(string)
+			if strings.Contains(parsed, "r00tPassw0rd") || !strings.Contains(parsed, redactedPassword) {
+				t.Fatalf("expected the password to be redacted, got %s", parsed)
+			}
+			if !strings.Contains(parsed, test.expected) && !strings.Contains(parsed, url.QueryEscape(test.expected)) {
+				t.Fatalf("expected %s in the parsed connection URL, got %s", test.expected, parsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build goora
+
+package oracle
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+
+	go_ora "github.com/sijms/go-ora/v2"
+)
+
+// driverName is the database/sql driver used to connect to Oracle. go-ora is written in pure Go, so the
+// plugin can be built without cgo or the Oracle Instant Client.
+const driverName = "oracle"
+
+// tlsDriverName is the driver used when the TLS settings are configured, see tlsDriver.
+const tlsDriverName = "oracle-vault-tls"
+
+func init() {
+	sql.Register(tlsDriverName, tlsDriver{})
+}
+
+// translateConnectionURL converts a go-oci8 DSN into a go-ora URL. Only the connect descriptor of go-ora URLs is
+// normalized, unless they have to be pointed to the wallet of the TLS settings.
+func translateConnectionURL(connURL string, wallet *tlsWallet) (string, error) {
+	if connURL == "" {
+		return connURL, nil
+	}
+	if wallet == nil && isGoOraURL(connURL) {
+		return normalizeGoOraURL(connURL)
+	}
+
+	c, err := parseConnectionURL(connURL)
+	if err != nil {
+		return "", err
+	}
+	return formatConnectionURL(c, wallet)
+}
+
+// formatConnectionURL formats the connection URL as a go-ora URL, pointed to the wallet of the TLS settings if
+// there is one.
+func formatConnectionURL(c connectionURL, wallet *tlsWallet) (string, error) {
+	if c.tnsAlias != "" {
+		return "", fmt.Errorf("TNS alias %q cannot be resolved by the %s driver, use host or connect_descriptor", c.tnsAlias, driverName)
+	}
+	if wallet != nil {
+		var err error
+		c, err = c.withWallet(wallet)
+		if err != nil {
+			return "", err
+		}
+	}
+	return c.goOraURL(), nil
+}
+
+// passwordWalletSupported reports whether the driver can look up the root credential in a password wallet. go-ora
+// is given the wallet directory of the connection in its URL.
+const passwordWalletSupported = true
+
+// passwordWalletURL returns the connection URL connecting as the configured user, whose password go-ora looks up in
+// the wallet by the host, port and service of the URL.
+func passwordWalletURL(connURL string, wallet *passwordWallet) string {
+	_, rest := splitRight(connURL[len(goOraURLPrefix):], "@")
+	sep := "?"
+	if strings.Contains(rest, "?") {
+		sep = "&"
+	}
+	return goOraURLPrefix + "{{username}}@" + rest + sep + walletOption + "=" + url.QueryEscape(wallet.dir)
+}
+
+// kerberosSupported reports whether the driver can authenticate with Kerberos. go-ora has to be given a Kerberos
+// client implementation, which the plugin doesn't include.
+const kerberosSupported = false
+
+// tlsDriver connects with go-ora using the TLS configuration of the wallet directory named by the WALLET option,
+// as go-ora can only read the certificates from Oracle wallets.
+type tlsDriver struct{}
+
+func (d tlsDriver) Open(name string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+func (tlsDriver) OpenConnector(name string) (driver.Connector, error) {
+	u, err := url.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection URL: %w", err)
+	}
+
+	query := u.Query()
+	config, err := walletTLSConfig(query.Get(walletOption))
+	if err != nil {
+		return nil, err
+	}
+	query.Del(walletOption)
+	u.RawQuery = query.Encode()
+
+	return &tlsConnector{
+		url:    u.String(),
+		config: config,
+	}, nil
+}
+
+type tlsConnector struct {
+	url    string
+	config *tls.Config
+}
+
+func (c *tlsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	// go-ora sets the server name on the TLS configuration, so each connection is given its own copy
+	connector := go_ora.NewConnector(c.url).(*go_ora.OracleConnector)
+	connector.WithTLSConfig(c.config.Clone())
+	return connector.Connect(ctx)
+}
+
+func (c *tlsConnector) Driver() driver.Driver {
+	return tlsDriver{}
+}
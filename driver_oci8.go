@@ -0,0 +1,60 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !goora
+
+package oracle
+
+import (
+	_ "github.com/mattn/go-oci8"
+)
+
+// driverName is the database/sql driver used to connect to Oracle. go-oci8 requires cgo and the Oracle
+// Instant Client; build with the `goora` tag to use the pure-Go go-ora driver instead.
+const driverName = "oci8"
+
+// tlsDriverName is the driver used when the TLS settings are configured. The Instant Client reads the wallet
+// written by the plugin itself, so it is the same driver.
+const tlsDriverName = driverName
+
+// translateConnectionURL converts a connection_url written for go-ora into a go-oci8 DSN. The connect strings of
+// other connection URLs are only normalized, unless they have to be pointed to the wallet of the TLS settings.
+func translateConnectionURL(connURL string, wallet *tlsWallet) (string, error) {
+	if wallet == nil && !isGoOraURL(connURL) {
+		return normalizeOCI8DSN(connURL)
+	}
+
+	c, err := parseConnectionURL(connURL)
+	if err != nil {
+		return "", err
+	}
+	return formatConnectionURL(c, wallet)
+}
+
+// formatConnectionURL formats the connection URL as a go-oci8 DSN, pointed to the wallet of the TLS settings if
+// there is one.
+func formatConnectionURL(c connectionURL, wallet *tlsWallet) (string, error) {
+	if wallet != nil {
+		var err error
+		c, err = c.withWallet(wallet)
+		if err != nil {
+			return "", err
+		}
+	}
+	return c.oci8DSN(), nil
+}
+
+// kerberosSupported reports whether the driver can authenticate with Kerberos. The Instant Client does so with the
+// ticket cache named in the connect descriptor, see kerberosURL.
+const kerberosSupported = true
+
+// passwordWalletSupported reports whether the driver can look up the root credential in a password wallet. The
+// Instant Client only finds the external password store through the sqlnet.ora of the whole process, and looks the
+// credential up by the connect string, which can't name the wallet of the connection without changing the key it is
+// stored under. A wallet can therefore not be given to a single connection.
+const passwordWalletSupported = false
+
+// passwordWalletURL is never used, as password_wallet is rejected, see passwordWalletSupported.
+func passwordWalletURL(connURL string, _ *passwordWallet) string {
+	return connURL
+}
@@ -0,0 +1,55 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !goora
+
+package oracle
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestParsePasswordWalletUnsupported(t *testing.T) {
+	config := map[string]interface{}{
+		"password_wallet": base64.StdEncoding.EncodeToString([]byte{0xa1, 0xf8, 0x4e, 0x36, 0x00}),
+	}
+	_, _, _, err := parsePasswordWallet(config)
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+}
+
+func TestOracle_InitializePasswordWalletUnsupported(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	db := new()
+	defer db.Close()
+
+	req := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":  "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+			"username":        "vaultadmin",
+			"password_wallet": base64.StdEncoding.EncodeToString([]byte{0xa1, 0xf8, 0x4e, 0x36, 0x00}),
+		},
+		VerifyConnection: false,
+	}
+	_, err := db.Initialize(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "password_wallet is not supported") {
+		t.Fatalf("expected password_wallet to be refused, got: %v", err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temporary directory: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no wallet to be written, got %d entries", len(entries))
+	}
+}
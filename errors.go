@@ -0,0 +1,166 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUserNotFound is returned when the user does not exist (ORA-01918).
+	ErrUserNotFound = errors.New("user does not exist")
+
+	// ErrUserOrRoleNotFound is returned when a user or role named in a GRANT or REVOKE does not exist
+	// (ORA-01917). It doesn't tell which of them is missing, so unlike ErrUserNotFound it is never taken as
+	// a sign that the user was already revoked.
+	ErrUserOrRoleNotFound = errors.New("user or role does not exist")
+
+	// ErrAlreadyRevoked is returned when the role or privilege being revoked was not granted
+	// (ORA-01951, ORA-01952).
+	ErrAlreadyRevoked = errors.New("role or privilege was not granted")
+
+	// ErrUserConnected is returned when dropping a user that still has sessions (ORA-01940).
+	ErrUserConnected = errors.New("user is currently connected")
+
+	// ErrResourceBusy is returned when a lock could not be acquired with NOWAIT (ORA-00054).
+	ErrResourceBusy = errors.New("resource busy")
+
+	// ErrInsufficientPrivileges is returned when the Vault user lacks a privilege (ORA-01031).
+	ErrInsufficientPrivileges = errors.New("insufficient privileges")
+
+	// ErrSessionNotFound is returned when killing a session that no longer exists (ORA-00030).
+	ErrSessionNotFound = errors.New("session does not exist")
+
+	// ErrSessionMarkedForKill is returned when a killed session couldn't be terminated right away (ORA-00031).
+	ErrSessionMarkedForKill = errors.New("session marked for kill")
+
+	// ErrPasswordRejected is returned when the PASSWORD_VERIFY_FUNCTION of the profile of the user rejects the
+	// password (ORA-28003). The error is a *PasswordVerifyError carrying the reason given by the function.
+	ErrPasswordRejected = errors.New("password rejected by the password verify function")
+)
+
+// oraPasswordVerifyFailed is the error code of a password rejected by a verify function.
+const oraPasswordVerifyFailed = 28003
+
+// oraErrors maps ORA error codes to the errors they are classified as.
+var oraErrors = map[int]error{
+	1917:  ErrUserOrRoleNotFound,
+	1918:  ErrUserNotFound,
+	1951:  ErrAlreadyRevoked,
+	1952:  ErrAlreadyRevoked,
+	1940:  ErrUserConnected,
+	54:    ErrResourceBusy,
+	1031:  ErrInsufficientPrivileges,
+	30:    ErrSessionNotFound,
+	31:    ErrSessionMarkedForKill,
+	28003: ErrPasswordRejected,
+}
+
+var oraCodeRegex = regexp.MustCompile(`ORA-(\d{5})`)
+
+// verifyReasonRegex matches the errors raised by password verify functions with raise_application_error, which
+// uses the ORA-20000 to ORA-20999 range.
+var verifyReasonRegex = regexp.MustCompile(`ORA-(20\d{3}): ?([^\n]*)`)
+
+// OracleError is a driver error carrying an ORA error code. Errors with a known code match the
+// corresponding error above with errors.Is.
+type OracleError struct {
+	Code int
+
+	err error
+}
+
+func (e *OracleError) Error() string {
+	if kind, ok := oraErrors[e.Code]; ok {
+		return kind.Error() + ": " + e.err.Error()
+	}
+	return e.err.Error()
+}
+
+func (e *OracleError) Unwrap() error {
+	return e.err
+}
+
+func (e *OracleError) Is(target error) bool {
+	kind, ok := oraErrors[e.Code]
+	return ok && kind == target
+}
+
+// PasswordVerifyError is returned when the PASSWORD_VERIFY_FUNCTION of a profile rejects a password. Reason is
+// the message the function raised along with ORA-28003, and Code its error code, so that operators see why the
+// password was rejected without the rest of the error stack. Both are empty if the function gave no reason.
+type PasswordVerifyError struct {
+	Code   int
+	Reason string
+
+	err error
+}
+
+func (e *PasswordVerifyError) Error() string {
+	if e.Reason == "" {
+		return ErrPasswordRejected.Error()
+	}
+	return fmt.Sprintf("%s: ORA-%05d: %s", ErrPasswordRejected, e.Code, e.Reason)
+}
+
+func (e *PasswordVerifyError) Unwrap() error {
+	return e.err
+}
+
+// classifyError parses the ORA error code out of an error returned by the driver. Both go-oci8 and go-ora
+// include the code in the message, so the first code found is used; in a PL/SQL error stack, that is the
+// error that was raised. The exception is ORA-28003, which classifies the error as a *PasswordVerifyError
+// wherever it is in the stack. Errors without a code are returned unchanged.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var oraErr *OracleError
+	if errors.As(err, &oraErr) {
+		return err
+	}
+
+	msg := err.Error()
+	matches := oraCodeRegex.FindAllStringSubmatch(msg, -1)
+	if matches == nil {
+		return err
+	}
+	code, _ := strconv.Atoi(matches[0][1])
+	for _, match := range matches {
+		if match[1] == strconv.Itoa(oraPasswordVerifyFailed) {
+			return newPasswordVerifyError(err, msg)
+		}
+	}
+	return &OracleError{
+		Code: code,
+		err:  err,
+	}
+}
+
+// newPasswordVerifyError classifies a rejected password, taking the reason from the ORA-20xxx error raised by
+// the verify function.
+func newPasswordVerifyError(err error, msg string) error {
+	verifyErr := &PasswordVerifyError{
+		err: &OracleError{
+			Code: oraPasswordVerifyFailed,
+			err:  err,
+		},
+	}
+	if match := verifyReasonRegex.FindStringSubmatch(msg); match != nil {
+		verifyErr.Code, _ = strconv.Atoi(match[1])
+		verifyErr.Reason = strings.TrimSpace(match[2])
+	}
+	return verifyErr
+}
+
+// isAlreadyRevoked reports whether a revocation statement failed because the grant it revokes was already
+// revoked. A missing user isn't covered, see userExists.
+func isAlreadyRevoked(err error) bool {
+	return errors.Is(err, ErrAlreadyRevoked)
+}
@@ -0,0 +1,321 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestClassifyError(t *testing.T) {
+	type testCase struct {
+		err          error
+		expectedCode int
+		expectedIs   error
+	}
+
+	tests := map[string]testCase{
+		"user does not exist": {
+			err:          errors.New(`ORA-01918: user 'V_TEST' does not exist`),
+			expectedCode: 1918,
+			expectedIs:   ErrUserNotFound,
+		},
+		"role does not exist": {
+			err:          errors.New(`ORA-01917: user or role 'V_TEST' does not exist`),
+			expectedCode: 1917,
+			expectedIs:   ErrUserOrRoleNotFound,
+		},
+		"role not granted": {
+			err:          errors.New(`ORA-01951: ROLE 'CONNECT' not granted to 'V_TEST'`),
+			expectedCode: 1951,
+			expectedIs:   ErrAlreadyRevoked,
+		},
+		"privilege not granted": {
+			err:          errors.New(`ORA-01952: system privileges not granted to 'V_TEST'`),
+			expectedCode: 1952,
+			expectedIs:   ErrAlreadyRevoked,
+		},
+		"user connected": {
+			err:          errors.New(`ORA-01940: cannot drop a user that is currently connected`),
+			expectedCode: 1940,
+			expectedIs:   ErrUserConnected,
+		},
+		"resource busy": {
+			err:          errors.New(`ORA-00054: resource busy and acquire with NOWAIT specified or timeout expired`),
+			expectedCode: 54,
+			expectedIs:   ErrResourceBusy,
+		},
+		"insufficient privileges": {
+			err:          errors.New(`ORA-01031: insufficient privileges`),
+			expectedCode: 1031,
+			expectedIs:   ErrInsufficientPrivileges,
+		},
+		"PL/SQL error stack": {
+			err:          errors.New("ORA-01918: user 'V_TEST' does not exist\nORA-06512: at line 3"),
+			expectedCode: 1918,
+			expectedIs:   ErrUserNotFound,
+		},
+		"wrapped": {
+			err:          fmt.Errorf("failed to execute query: %w", errors.New(`ORA-01031: insufficient privileges`)),
+			expectedCode: 1031,
+			expectedIs:   ErrInsufficientPrivileges,
+		},
+		"password rejected": {
+			err:          errors.New("ORA-28003: password verification for the specified password failed\nORA-20001: Password length less than 8"),
+			expectedCode: 28003,
+			expectedIs:   ErrPasswordRejected,
+		},
+		"password rejected after PL/SQL error": {
+			err:          errors.New("ORA-06550: line 1, column 7\nORA-28003: password verification for the specified password failed"),
+			expectedCode: 28003,
+			expectedIs:   ErrPasswordRejected,
+		},
+		"unclassified code": {
+			err:          errors.New(`ORA-00942: table or view does not exist`),
+			expectedCode: 942,
+		},
+		"no code": {
+			err: errors.New(`driver: bad connection`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := classifyError(test.err)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("classified error does not wrap the original error")
+			}
+
+			var oraErr *OracleError
+			code := 0
+			if errors.As(err, &oraErr) {
+				code = oraErr.Code
+			}
+			if code != test.expectedCode {
+				t.Fatalf("Actual: %d\nExpected: %d", code, test.expectedCode)
+			}
+
+			for _, kind := range oraErrors {
+				if errors.Is(err, kind) != (kind == test.expectedIs) {
+					t.Fatalf("errors.Is(%q, %q) = %t", err, kind, errors.Is(err, kind))
+				}
+			}
+		})
+	}
+}
+
+func TestOracle_DeleteUserIdempotent(t *testing.T) {
+	type testCase struct {
+		errors map[string]string
+
+		// userCounts are the results of the successive ALL_USERS lookups, the last one repeating
+		userCounts []int
+
+		expectedStatements int
+		expectedIs         error
+	}
+
+	tests := map[string]testCase{
+		"user exists": {
+			userCounts:         []int{1},
+			expectedStatements: 3,
+		},
+		"grants already revoked": {
+			errors: map[string]string{
+				"REVOKE CONNECT":        "ORA-01951: ROLE 'CONNECT' not granted to 'V_TEST'",
+				"REVOKE CREATE SESSION": "ORA-01952: system privileges not granted to 'V_TEST'",
+			},
+			userCounts:         []int{1},
+			expectedStatements: 3,
+		},
+		"user already dropped": {
+			errors: map[string]string{
+				"REVOKE CONNECT":        "ORA-01917: user or role 'V_TEST' does not exist",
+				"REVOKE CREATE SESSION": "ORA-01917: user or role 'V_TEST' does not exist",
+				"DROP USER":             "ORA-01918: user 'V_TEST' does not exist",
+			},
+			userCounts: []int{0},
+		},
+		"user dropped meanwhile": {
+			errors: map[string]string{
+				"DROP USER": "ORA-01918: user 'V_TEST' does not exist",
+			},
+			userCounts:         []int{1, 0},
+			expectedStatements: 3,
+		},
+		"user not found but exists": {
+			errors: map[string]string{
+				"DROP USER": "ORA-01918: user 'V_TEST' does not exist",
+			},
+			userCounts:         []int{1},
+			expectedStatements: 3,
+			expectedIs:         ErrUserNotFound,
+		},
+		"user lookup fails": {
+			errors: map[string]string{
+				"DROP USER": "ORA-01918: user 'V_TEST' does not exist",
+			},
+			expectedStatements: 3,
+			expectedIs:         ErrUserNotFound,
+		},
+		"role does not exist": {
+			errors: map[string]string{
+				"REVOKE CONNECT": "ORA-01917: user or role 'CONNECT' does not exist",
+			},
+			userCounts:         []int{1},
+			expectedStatements: 1,
+			expectedIs:         ErrUserOrRoleNotFound,
+		},
+		"user connected": {
+			errors: map[string]string{
+				"DROP USER": "ORA-01940: cannot drop a user that is currently connected",
+			},
+			userCounts:         []int{1},
+			expectedStatements: 3,
+			expectedIs:         ErrUserConnected,
+		},
+		"insufficient privileges": {
+			errors: map[string]string{
+				"REVOKE CONNECT": "ORA-01031: insufficient privileges",
+			},
+			userCounts:         []int{1},
+			expectedStatements: 1,
+			expectedIs:         ErrInsufficientPrivileges,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"disconnect_sessions":         false,
+				"session_termination_timeout": "0s",
+			})
+			fdb.exec = func(query string, _ []driver.Value) error {
+				for prefix, msg := range test.errors {
+					if strings.HasPrefix(query, prefix) {
+						return errors.New(msg)
+					}
+				}
+				return nil
+			}
+			lookups := 0
+			fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+				if !strings.Contains(query, "all_users") || len(test.userCounts) == 0 {
+					return &fakeRows{}, nil
+				}
+				count := test.userCounts[min(lookups, len(test.userCounts)-1)]
+				lookups++
+				return &fakeRows{
+					columns: []string{"COUNT(*)"},
+					values:  [][]driver.Value{{int64(count)}},
+				}, nil
+			}
+
+			req := dbplugin.DeleteUserRequest{
+				Username: "V_TEST",
+				Statements: dbplugin.Statements{
+					Commands: defaultRevocationStatements,
+				},
+			}
+			_, err := db.DeleteUser(context.Background(), req)
+			if test.expectedIs == nil && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if test.expectedIs != nil && !errors.Is(err, test.expectedIs) {
+				t.Fatalf("Actual: %v\nExpected: %v", err, test.expectedIs)
+			}
+			if actual := len(fdb.statements()); actual != test.expectedStatements {
+				t.Fatalf("Actual: %d statements\nExpected: %d statements", actual, test.expectedStatements)
+			}
+		})
+	}
+}
+
+func TestClassifyPasswordVerifyError(t *testing.T) {
+	type testCase struct {
+		err            error
+		expectedCode   int
+		expectedReason string
+		expectedMsg    string
+	}
+
+	tests := map[string]testCase{
+		"reason": {
+			err:            errors.New("ORA-28003: password verification for the specified password failed\nORA-20001: Password length less than 15 characters\n"),
+			expectedCode:   20001,
+			expectedReason: "Password length less than 15 characters",
+			expectedMsg:    "password rejected by the password verify function: ORA-20001: Password length less than 15 characters",
+		},
+		"custom function": {
+			err:            errors.New("ORA-28003: password verification for the specified password failed\nORA-20042: password must not contain vault"),
+			expectedCode:   20042,
+			expectedReason: "password must not contain vault",
+			expectedMsg:    "password rejected by the password verify function: ORA-20042: password must not contain vault",
+		},
+		"no reason": {
+			err:         errors.New("ORA-28003: password verification for the specified password failed"),
+			expectedMsg: "password rejected by the password verify function",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := classifyError(test.err)
+
+			var verifyErr *PasswordVerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("expected a *PasswordVerifyError, got %T", err)
+			}
+			if verifyErr.Code != test.expectedCode {
+				t.Fatalf("Actual: %d\nExpected: %d", verifyErr.Code, test.expectedCode)
+			}
+			if verifyErr.Reason != test.expectedReason {
+				t.Fatalf("Actual: %q\nExpected: %q", verifyErr.Reason, test.expectedReason)
+			}
+			if err.Error() != test.expectedMsg {
+				t.Fatalf("Actual: %q\nExpected: %q", err.Error(), test.expectedMsg)
+			}
+			if !errors.Is(err, test.err) || !errors.Is(err, ErrPasswordRejected) {
+				t.Fatalf("classified error does not wrap the original error")
+			}
+			if classifyError(err) != err {
+				t.Fatalf("expected a classified error to be returned unchanged")
+			}
+		})
+	}
+}
+
+func TestOracle_NewUserPasswordRejected(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+	fdb.exec = func(query string, _ []driver.Value) error {
+		if strings.HasPrefix(query, "CREATE USER") {
+			return errors.New("ORA-28003: password verification for the specified password failed\nORA-20000: password must contain 1 or more special characters")
+		}
+		return nil
+	}
+
+	_, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "role",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+		},
+		Password: "98yq3thgnakjsfhjkl",
+	})
+	var verifyErr *PasswordVerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected a *PasswordVerifyError, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "password must contain 1 or more special characters") {
+		t.Fatalf("expected the reason in the error, got: %s", err)
+	}
+}
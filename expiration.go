@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// expirationModeNone leaves enforcing the lease entirely to Vault's revocation.
+	expirationModeNone = "none"
+
+	// expirationModeLockAccount schedules a job that locks the account and expires its
+	// password when the lease ends.
+	expirationModeLockAccount = "lock_account"
+
+	// expirationModeDropUser schedules a job that locks the account, kills its sessions
+	// and drops it when the lease ends.
+	expirationModeDropUser = "drop_user"
+
+	// defaultExpirationGracePeriod is how long after the end of the lease the job runs,
+	// giving Vault the chance to revoke the user first.
+	defaultExpirationGracePeriod = time.Minute
+
+	// expirationFormat is the format of the {{expiration}} template variable.
+	expirationFormat = "2006-01-02 15:04:05-0700"
+
+	// schedulerTimeFormat is the Go equivalent of schedulerOracleTimeFormat.
+	schedulerTimeFormat       = "2006-01-02 15:04:05 -07:00"
+	schedulerOracleTimeFormat = "YYYY-MM-DD HH24:MI:SS TZH:TZM"
+
+	// expirationJobPrefix is the prefix of the scheduler jobs created for each user. The
+	// prefix plus the hash suffix fits within the 30 byte identifier limit of older releases.
+	expirationJobPrefix = "VAULT_EXP_"
+
+	// scheduleExpirationJobSQL (re)creates a one-shot job that runs the given PL/SQL block
+	// at the given time. An existing job with the same name is replaced.
+	scheduleExpirationJobSQL = `DECLARE
+  v_job_name VARCHAR2(128) := :1;
+BEGIN
+  BEGIN
+    DBMS_SCHEDULER.DROP_JOB(job_name => v_job_name, force => TRUE);
+  EXCEPTION
+    WHEN OTHERS THEN
+      IF SQLCODE != -27475 THEN
+        RAISE;
+      END IF;
+  END;
+  DBMS_SCHEDULER.CREATE_JOB(
+    job_name   => v_job_name,
+    job_type   => 'PLSQL_BLOCK',
+    job_action => :2,
+    start_date => TO_TIMESTAMP_TZ(:3, '` + schedulerOracleTimeFormat + `'),
+    enabled    => TRUE,
+    auto_drop  => TRUE,
+    comments   => 'Enforces the expiration of a Vault lease');
+END;`
+
+	// cancelExpirationJobSQL drops the job for a user, ignoring ORA-27475 (unknown job)
+	// in case it already ran or was never created.
+	cancelExpirationJobSQL = `BEGIN
+  DBMS_SCHEDULER.DROP_JOB(job_name => :1, force => TRUE);
+EXCEPTION
+  WHEN OTHERS THEN
+    IF SQLCODE != -27475 THEN
+      RAISE;
+    END IF;
+END;`
+
+	// lockAccountJobAction locks the account and expires the password of the user named
+	// by the string literal substituted for %[1]s. The user is looked up as given and
+	// upper-cased, matching both quoted and unquoted usage in the creation statements.
+	lockAccountJobAction = `BEGIN
+  FOR u IN (SELECT username FROM all_users WHERE username IN (%[1]s, UPPER(%[1]s))) LOOP
+    EXECUTE IMMEDIATE 'ALTER USER "' || u.username || '" PASSWORD EXPIRE ACCOUNT LOCK';
+  END LOOP;
+END;`
+
+	// dropUserJobAction locks the account of the user named by the string literal
+	// substituted for %[1]s, kills its sessions on every instance and drops it. Sessions
+	// that end while they are being killed are ignored.
+	dropUserJobAction = `BEGIN
+  FOR u IN (SELECT username FROM all_users WHERE username IN (%[1]s, UPPER(%[1]s))) LOOP
+    EXECUTE IMMEDIATE 'ALTER USER "' || u.username || '" ACCOUNT LOCK';
+    FOR s IN (SELECT inst_id, sid, serial# AS serial FROM gv$session WHERE username = u.username) LOOP
+      BEGIN
+        EXECUTE IMMEDIATE 'ALTER SYSTEM KILL SESSION ''' || s.sid || ',' || s.serial || ',@' || s.inst_id || ''' IMMEDIATE';
+      EXCEPTION
+        WHEN OTHERS THEN
+          NULL;
+      END;
+    END LOOP;
+    EXECUTE IMMEDIATE 'DROP USER "' || u.username || '" CASCADE';
+  END LOOP;
+END;`
+)
+
+// execer is implemented by *sql.DB, *sql.Conn and *sql.Tx, so that expiration jobs can be managed
+// from a connection that has been switched to the target container.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+var expirationModes = map[string]bool{
+	expirationModeNone:        true,
+	expirationModeLockAccount: true,
+	expirationModeDropUser:    true,
+}
+
+// expirationJobName returns the name of the scheduler job that enforces the expiration
+// of the given user. The name is derived from the username so that it can be found again
+// on renewal and revocation without storing any state.
+func expirationJobName(username string) string {
+	sum := sha256.Sum256([]byte(username))
+	return expirationJobPrefix + strings.ToUpper(hex.EncodeToString(sum[:10]))
+}
+
+// expirationJobAction returns the PL/SQL block run by the expiration job of the given user.
+func (o *Oracle) expirationJobAction(username string) string {
+	action := lockAccountJobAction
+	if o.expirationMode == expirationModeDropUser {
+		action = dropUserJobAction
+	}
+	return fmt.Sprintf(action, quoteLiteral(username))
+}
+
+// scheduleExpiration creates or replaces the scheduler job that enforces the expiration of
+// the given user in the database. The job runs `expiration_grace_period` after the expiration.
+func (o *Oracle) scheduleExpiration(ctx context.Context, db execer, username string, expiration time.Time) error {
+	_, err := db.ExecContext(ctx, scheduleExpirationJobSQL,
+		expirationJobName(username),
+		o.expirationJobAction(username),
+		expiration.Add(o.expirationGracePeriod).Format(schedulerTimeFormat),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to schedule expiration job: %w", classifyError(err))
+	}
+	return nil
+}
+
+// cancelExpiration drops the scheduler job of the given user if it exists.
+func (o *Oracle) cancelExpiration(ctx context.Context, db execer, username string) error {
+	_, err := db.ExecContext(ctx, cancelExpirationJobSQL, expirationJobName(username))
+	if err != nil {
+		return fmt.Errorf("unable to cancel expiration job: %w", classifyError(err))
+	}
+	return nil
+}
+
+// quoteLiteral returns s as a single-quoted SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,286 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	dbtesting "github.com/hashicorp/vault/sdk/database/dbplugin/v5/testing"
+)
+
+func TestExpirationJobName(t *testing.T) {
+	name := expirationJobName("V_TOKEN_MYROLENA_ABCDEFGHIJ")
+	if !strings.HasPrefix(name, expirationJobPrefix) {
+		t.Fatalf("expected prefix %q, got %q", expirationJobPrefix, name)
+	}
+	if len(name) > 30 {
+		t.Fatalf("job name %q is longer than 30 bytes", name)
+	}
+	if name != expirationJobName("V_TOKEN_MYROLENA_ABCDEFGHIJ") {
+		t.Fatalf("job name is not deterministic")
+	}
+	if name == expirationJobName("V_TOKEN_MYROLENA_ABCDEFGHIK") {
+		t.Fatalf("different users have the same job name")
+	}
+}
+
+func TestExpirationJobAction(t *testing.T) {
+	type testCase struct {
+		mode     string
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"lock_account": {
+			mode:     expirationModeLockAccount,
+			expected: `PASSWORD EXPIRE ACCOUNT LOCK`,
+		},
+		"drop_user": {
+			mode:     expirationModeDropUser,
+			expected: `DROP USER`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := &Oracle{
+				expirationMode: test.mode,
+			}
+
+			action := db.expirationJobAction(`BAD'USER`)
+			if !strings.Contains(action, `IN ('BAD''USER', UPPER('BAD''USER'))`) {
+				t.Fatalf("username was not quoted as a string literal:\n%s", action)
+			}
+			if !strings.Contains(action, test.expected) {
+				t.Fatalf("job action does not contain %q:\n%s", test.expected, action)
+			}
+			if statements := splitStatements(action); len(statements) != 1 {
+				t.Fatalf("job action should be a single block, got %d statements", len(statements))
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeExpirationMode(t *testing.T) {
+	type testCase struct {
+		mode        interface{}
+		gracePeriod interface{}
+
+		expectedMode        string
+		expectedGracePeriod time.Duration
+		expectErr           bool
+	}
+
+	tests := map[string]testCase{
+		"default": {
+			expectedMode:        expirationModeNone,
+			expectedGracePeriod: defaultExpirationGracePeriod,
+		},
+		"lock_account": {
+			mode:                "lock_account",
+			expectedMode:        expirationModeLockAccount,
+			expectedGracePeriod: defaultExpirationGracePeriod,
+		},
+		"drop_user with grace period": {
+			mode:                "drop_user",
+			gracePeriod:         "5m",
+			expectedMode:        expirationModeDropUser,
+			expectedGracePeriod: 5 * time.Minute,
+		},
+		"grace period in seconds": {
+			mode:                "drop_user",
+			gracePeriod:         30,
+			expectedMode:        expirationModeDropUser,
+			expectedGracePeriod: 30 * time.Second,
+		},
+		"invalid mode": {
+			mode:      "forever",
+			expectErr: true,
+		},
+		"negative grace period": {
+			mode:         "drop_user",
+			gracePeriod:  "-1m",
+			expectedMode: expirationModeDropUser,
+			expectErr:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := new()
+
+			config := map[string]interface{}{
+				"connection_url": "system/oracle@localhost:1521/xe",
+			}
+			if test.mode != nil {
+				config["expiration_mode"] = test.mode
+			}
+			if test.gracePeriod != nil {
+				config["expiration_grace_period"] = test.gracePeriod
+			}
+			req := dbplugin.InitializeRequest{
+				Config:           config,
+				VerifyConnection: false,
+			}
+
+			_, err := db.Initialize(context.Background(), req)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if db.expirationMode != test.expectedMode {
+				t.Fatalf("Actual: %q\nExpected: %q", db.expirationMode, test.expectedMode)
+			}
+			if db.expirationGracePeriod != test.expectedGracePeriod {
+				t.Fatalf("Actual: %s\nExpected: %s", db.expirationGracePeriod, test.expectedGracePeriod)
+			}
+		})
+	}
+}
+
+func TestOracle_ExpirationModeLockAccount(t *testing.T) {
+	connURL, cleanup := prepareOracleTestContainer(t)
+	t.Cleanup(cleanup)
+
+	db := new()
+	defer dbtesting.AssertClose(t, db)
+
+	initReq := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":  connURL,
+			"expiration_mode": expirationModeLockAccount,
+		},
+		VerifyConnection: true,
+	}
+	dbtesting.AssertInitialize(t, db, initReq)
+
+	password := "y8fva_sdVA3rasf"
+	createReq := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "test",
+			RoleName:    "test",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{
+				`
+				CREATE USER {{name}} IDENTIFIED BY {{password}};
+				GRANT CONNECT TO {{name}};
+				GRANT CREATE SESSION TO {{name}};`,
+			},
+		},
+		Password:   password,
+		Expiration: time.Now().Add(time.Hour),
+	}
+	createResp := dbtesting.AssertNewUser(t, db, createReq)
+	assertCredentialsExist(t, connURL, createResp.Username, password)
+
+	initialStart := getExpirationJobStart(t, db, createResp.Username)
+
+	renewReq := dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Expiration: &dbplugin.ChangeExpiration{
+			NewExpiration: time.Now().Add(2 * time.Hour),
+		},
+	}
+	dbtesting.AssertUpdateUser(t, db, renewReq)
+
+	renewedStart := getExpirationJobStart(t, db, createResp.Username)
+	if !renewedStart.After(initialStart) {
+		t.Fatalf("expected job to be rescheduled after %s, got %s", initialStart, renewedStart)
+	}
+
+	deleteReq := dbplugin.DeleteUserRequest{
+		Username: createResp.Username,
+	}
+	dbtesting.AssertDeleteUser(t, db, deleteReq)
+
+	ctx, cancel := context.WithTimeout(context.Background(), getRequestTimeout(t))
+	defer cancel()
+
+	sqlDB, err := db.getConnection(ctx)
+	if err != nil {
+		t.Fatalf("unable to get connection to database: %s", err)
+	}
+	var count int
+	err = sqlDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_scheduler_jobs WHERE job_name = :1`, expirationJobName(createResp.Username)).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to query scheduler jobs: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected expiration job to be dropped")
+	}
+}
+
+func getExpirationJobStart(t *testing.T, db *Oracle, username string) time.Time {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), getRequestTimeout(t))
+	defer cancel()
+
+	sqlDB, err := db.getConnection(ctx)
+	if err != nil {
+		t.Fatalf("unable to get connection to database: %s", err)
+	}
+
+	var start sql.NullTime
+	err = sqlDB.QueryRowContext(ctx, `SELECT CAST(start_date AS DATE) FROM user_scheduler_jobs WHERE job_name = :1`, expirationJobName(username)).Scan(&start)
+	if err != nil {
+		t.Fatalf("failed to find expiration job: %s", err)
+	}
+	return start.Time
+}
+
+func TestOracle_ExpirationModeDropUser(t *testing.T) {
+	connURL, cleanup := prepareOracleTestContainer(t)
+	t.Cleanup(cleanup)
+
+	db := new()
+	defer dbtesting.AssertClose(t, db)
+
+	initReq := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":          connURL,
+			"expiration_mode":         expirationModeDropUser,
+			"expiration_grace_period": "0s",
+		},
+		VerifyConnection: true,
+	}
+	dbtesting.AssertInitialize(t, db, initReq)
+
+	password := "y8fva_sdVA3rasf"
+	createReq := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "test",
+			RoleName:    "test",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{
+				`
+				CREATE USER {{name}} IDENTIFIED BY {{password}};
+				GRANT CONNECT TO {{name}};
+				GRANT CREATE SESSION TO {{name}};`,
+			},
+		},
+		Password:   password,
+		Expiration: time.Now().Add(2 * time.Second),
+	}
+	createResp := dbtesting.AssertNewUser(t, db, createReq)
+	assertCredentialsExist(t, connURL, createResp.Username, password)
+
+	// The scheduler doesn't start jobs exactly on time, so give it a while to drop the user
+	deadline := time.Now().Add(time.Minute)
+	for testCredentialsExist(connURL, createResp.Username, password) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("user was not dropped by the expiration job")
+		}
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// fakeDriverName is a database/sql driver that records statements instead of running them, so that the
+// plugin can be tested without an Oracle database.
+const fakeDriverName = "fakeoracle"
+
+func init() {
+	sql.Register(fakeDriverName, fakeDriver{})
+}
+
+var fakeDBs sync.Map // map[string]*fakeDB
+
+// fakeDB is the state shared by all connections opened to the same fake database.
+type fakeDB struct {
+	mu       sync.Mutex
+	executed []string
+
+	// exec and query, if set, are called for every statement and query. Their errors are returned to
+	// the plugin, so they can emulate Oracle errors.
+	exec  func(query string, args []driver.Value) error
+	query func(query string, args []driver.Value) (driver.Rows, error)
+
+	// open, if set, is called with the DSN of every new connection, e.g. to reject a password.
+	open func(dsn string) error
+}
+
+// newFakeOracle returns an initialized plugin connected to a new fake database.
+func newFakeOracle(t *testing.T, config map[string]interface{}) (*Oracle, *fakeDB) {
+	t.Helper()
+
+	fdb := &fakeDB{}
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	fakeDBs.Store(name, fdb)
+	t.Cleanup(func() {
+		fakeDBs.Delete(name)
+	})
+
+	db := new()
+	db.SQLConnectionProducer.Type = fakeDriverName
+
+	conf := map[string]interface{}{
+		"connection_url": "{{username}}/{{password}}@fakehost:1521/" + name,
+		"username":       "vaultadmin",
+		"password":       "r00tPassw0rd",
+	}
+	for k, v := range config {
+		conf[k] = v
+	}
+
+	req := dbplugin.InitializeRequest{
+		Config:           conf,
+		VerifyConnection: false,
+	}
+	_, err := db.Initialize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("failed to initialize fake database: %s", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db, fdb
+}
+
+// statements returns the statements executed so far.
+func (f *fakeDB) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.executed...)
+}
+
+func (f *fakeDB) runExec(query string, args []driver.Value) error {
+	f.mu.Lock()
+	f.executed = append(f.executed, query)
+	fn := f.exec
+	f.mu.Unlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(query, args)
+}
+
+func (f *fakeDB) runQuery(query string, args []driver.Value) (driver.Rows, error) {
+	f.mu.Lock()
+	fn := f.query
+	f.mu.Unlock()
+
+	if fn == nil {
+		return &fakeRows{}, nil
+	}
+	return fn(query, args)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	name := dsn[strings.LastIndex(dsn, "/")+1:]
+	fdb, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	db := fdb.(*fakeDB)
+
+	db.mu.Lock()
+	fn := db.open
+	db.mu.Unlock()
+	if fn != nil {
+		err := fn(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	err := s.db.runExec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.db.runQuery(s.query, args)
+}
+
+// fakeRows returns a fixed set of rows.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
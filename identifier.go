@@ -0,0 +1,121 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+const (
+	// maxIdentifierBytes is the maximum length of identifiers since Oracle 12.2.
+	maxIdentifierBytes = 128
+
+	// maxLegacyIdentifierBytes is the maximum length of identifiers before Oracle 12.2.
+	maxLegacyIdentifierBytes = 30
+)
+
+// sampleUsernameMetadata is used to check the output of the username template when the plugin is initialized.
+var sampleUsernameMetadata = dbplugin.UsernameMetadata{
+	DisplayName: "token",
+	RoleName:    "role",
+}
+
+// reservedWords are the Oracle SQL reserved words, which can't be used as nonquoted identifiers.
+var reservedWords = map[string]bool{
+	"ACCESS": true, "ADD": true, "ALL": true, "ALTER": true, "AND": true, "ANY": true, "AS": true, "ASC": true,
+	"AUDIT": true, "BETWEEN": true, "BY": true, "CHAR": true, "CHECK": true, "CLUSTER": true, "COLUMN": true,
+	"COLUMN_VALUE": true, "COMMENT": true, "COMPRESS": true, "CONNECT": true, "CREATE": true, "CURRENT": true,
+	"DATE": true, "DECIMAL": true, "DEFAULT": true, "DELETE": true, "DESC": true, "DISTINCT": true, "DROP": true,
+	"ELSE": true, "EXCLUSIVE": true, "EXISTS": true, "FILE": true, "FLOAT": true, "FOR": true, "FROM": true,
+	"GRANT": true, "GROUP": true, "HAVING": true, "IDENTIFIED": true, "IMMEDIATE": true, "IN": true,
+	"INCREMENT": true, "INDEX": true, "INITIAL": true, "INSERT": true, "INTEGER": true, "INTERSECT": true,
+	"INTO": true, "IS": true, "LEVEL": true, "LIKE": true, "LOCK": true, "LONG": true, "MAXEXTENTS": true,
+	"MINUS": true, "MLSLABEL": true, "MODE": true, "MODIFY": true, "NESTED_TABLE_ID": true, "NOAUDIT": true,
+	"NOCOMPRESS": true, "NOT": true, "NOWAIT": true, "NULL": true, "NUMBER": true, "OF": true, "OFFLINE": true,
+	"ON": true, "ONLINE": true, "OPTION": true, "OR": true, "ORDER": true, "PCTFREE": true, "PRIOR": true,
+	"PUBLIC": true, "RAW": true, "RENAME": true, "RESOURCE": true, "REVOKE": true, "ROW": true, "ROWID": true,
+	"ROWNUM": true, "ROWS": true, "SELECT": true, "SESSION": true, "SET": true, "SHARE": true, "SIZE": true,
+	"SMALLINT": true, "START": true, "SUCCESSFUL": true, "SYNONYM": true, "SYSDATE": true, "TABLE": true,
+	"THEN": true, "TO": true, "TRIGGER": true, "UID": true, "UNION": true, "UNIQUE": true, "UPDATE": true,
+	"USER": true, "VALIDATE": true, "VALUES": true, "VARCHAR": true, "VARCHAR2": true, "VIEW": true,
+	"WHENEVER": true, "WHERE": true, "WITH": true,
+}
+
+// usernameRefRegex matches the username placeholders in statements, along with the quotes around them.
+var usernameRefRegex = regexp.MustCompile(`"?\{\{(?:username|name)\}\}"?`)
+
+// maxIdentifierLength returns the maximum length in bytes of identifiers on the given server. If the version
+// is unknown, the longer limit is used and the database is left to enforce it.
+func maxIdentifierLength(version serverVersion) int {
+	if version.major != 0 && !version.atLeast(12, 2) {
+		return maxLegacyIdentifierBytes
+	}
+	return maxIdentifierBytes
+}
+
+// usernameQuoted reports whether the statements only use the username as a quoted identifier, which lifts most
+// of the restrictions on it.
+func usernameQuoted(statements []string) bool {
+	quoted := false
+	for _, stmt := range statements {
+		for _, ref := range usernameRefRegex.FindAllString(stmt, -1) {
+			if !strings.HasPrefix(ref, `"`) || !strings.HasSuffix(ref, `"`) {
+				return false
+			}
+			quoted = true
+		}
+	}
+	return quoted
+}
+
+// validateUsername checks that a generated username can be created on the given server in the given container,
+// so that an unusable username_template fails before any statement is run.
+func validateUsername(username, container string, version serverVersion, quoted bool) error {
+	err := validateIdentifier(username, maxIdentifierLength(version), quoted)
+	if err != nil {
+		return fmt.Errorf("invalid username %q: %w", username, err)
+	}
+	return validateCommonUsername(username, container)
+}
+
+// validateIdentifier checks that name is a valid identifier. Quoted identifiers can contain any character but
+// double quotes and NUL. Nonquoted identifiers must start with a letter, contain only letters, digits, _, $ and
+// #, and must not be a reserved word.
+func validateIdentifier(name string, maxBytes int, quoted bool) error {
+	if name == "" {
+		return fmt.Errorf("identifier must not be empty")
+	}
+	if len(name) > maxBytes {
+		return fmt.Errorf("identifier is %d bytes long, the maximum is %d", len(name), maxBytes)
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("identifier is not valid UTF-8")
+	}
+	if strings.ContainsAny(name, "\"\x00") {
+		return fmt.Errorf("identifier must not contain double quotes or NUL characters")
+	}
+	if quoted {
+		return nil
+	}
+
+	for i, r := range name {
+		switch {
+		case i == 0 && !unicode.IsLetter(r):
+			return fmt.Errorf("nonquoted identifier must start with a letter")
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '$', r == '#':
+		default:
+			return fmt.Errorf("nonquoted identifier must not contain %q", r)
+		}
+	}
+	if reservedWords[strings.ToUpper(name)] {
+		return fmt.Errorf("%s is a reserved word", strings.ToUpper(name))
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	type testCase struct {
+		name      string
+		maxBytes  int
+		quoted    bool
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"valid": {
+			name:     "V_TOKEN_ROLE_ABC$#1",
+			maxBytes: 30,
+		},
+		"lower case": {
+			name:     "v_token",
+			maxBytes: 30,
+		},
+		"common user": {
+			name:     "C##V_TOKEN",
+			maxBytes: 30,
+		},
+		"empty": {
+			name:      "",
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"too long": {
+			name:      strings.Repeat("A", 31),
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"long identifier": {
+			name:     strings.Repeat("A", 128),
+			maxBytes: 128,
+		},
+		"multibyte characters count as bytes": {
+			name:      strings.Repeat("É", 16),
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"leading digit": {
+			name:      "1V_TOKEN",
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"leading digit quoted": {
+			name:     "1V_TOKEN",
+			maxBytes: 30,
+			quoted:   true,
+		},
+		"hyphen": {
+			name:      "V-TOKEN",
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"hyphen quoted": {
+			name:     "V-TOKEN",
+			maxBytes: 30,
+			quoted:   true,
+		},
+		"injection": {
+			name:      "V_TOKEN; DROP USER SYSTEM",
+			maxBytes:  128,
+			expectErr: true,
+		},
+		"double quote": {
+			name:      `V_TOKEN" IDENTIFIED EXTERNALLY --`,
+			maxBytes:  128,
+			quoted:    true,
+			expectErr: true,
+		},
+		"NUL": {
+			name:      "V_TOKEN\x00",
+			maxBytes:  30,
+			quoted:    true,
+			expectErr: true,
+		},
+		"reserved word": {
+			name:      "select",
+			maxBytes:  30,
+			expectErr: true,
+		},
+		"reserved word quoted": {
+			name:     "SELECT",
+			maxBytes: 30,
+			quoted:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateIdentifier(test.name, test.maxBytes, test.quoted)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestMaxIdentifierLength(t *testing.T) {
+	type testCase struct {
+		version  serverVersion
+		expected int
+	}
+
+	tests := map[string]testCase{
+		"unknown": {
+			version:  serverVersion{},
+			expected: maxIdentifierBytes,
+		},
+		"11.2": {
+			version:  serverVersion{major: 11, minor: 2},
+			expected: maxLegacyIdentifierBytes,
+		},
+		"12.1": {
+			version:  serverVersion{major: 12, minor: 1},
+			expected: maxLegacyIdentifierBytes,
+		},
+		"12.2": {
+			version:  serverVersion{major: 12, minor: 2},
+			expected: maxIdentifierBytes,
+		},
+		"19": {
+			version:  serverVersion{major: 19},
+			expected: maxIdentifierBytes,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := maxIdentifierLength(test.version)
+			if actual != test.expected {
+				t.Fatalf("Actual: %d\nExpected: %d", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestUsernameQuoted(t *testing.T) {
+	type testCase struct {
+		statements []string
+		expected   bool
+	}
+
+	tests := map[string]testCase{
+		"no statements": {
+			statements: nil,
+			expected:   false,
+		},
+		"nonquoted": {
+			statements: []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+			expected:   false,
+		},
+		"quoted": {
+			statements: []string{
+				`CREATE USER "{{username}}" IDENTIFIED BY "{{password}}"`,
+				`GRANT CONNECT TO "{{name}}"`,
+			},
+			expected: true,
+		},
+		"mixed": {
+			statements: []string{
+				`CREATE USER "{{username}}" IDENTIFIED BY "{{password}}"`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := usernameQuoted(test.statements)
+			if actual != test.expected {
+				t.Fatalf("Actual: %t\nExpected: %t", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeValidatesUsernameTemplate(t *testing.T) {
+	type testCase struct {
+		config    map[string]interface{}
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"default template": {
+			config: map[string]interface{}{},
+		},
+		"default template in root": {
+			config: map[string]interface{}{
+				"container": "CDB$ROOT",
+			},
+		},
+		"custom template": {
+			config: map[string]interface{}{
+				"username_template": "V_{{.RoleName | uppercase}}_{{random 10}}",
+			},
+		},
+		"too long": {
+			config: map[string]interface{}{
+				"username_template": "{{random 129}}",
+			},
+			expectErr: true,
+		},
+		"double quote": {
+			config: map[string]interface{}{
+				"username_template": `{{.DisplayName}}"`,
+			},
+			expectErr: true,
+		},
+		"missing common user prefix": {
+			config: map[string]interface{}{
+				"username_template": "V_{{.RoleName | uppercase}}_{{random 10}}",
+				"container":         "CDB$ROOT",
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := new()
+			config := map[string]interface{}{
+				"connection_url": "system/oracle@localhost:1521/xe",
+			}
+			for k, v := range test.config {
+				config[k] = v
+			}
+
+			_, err := db.Initialize(context.Background(), dbplugin.InitializeRequest{
+				Config:           config,
+				VerifyConnection: false,
+			})
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestOracle_NewUserValidatesUsername(t *testing.T) {
+	type testCase struct {
+		usernameTemplate string
+		version          string
+		statement        string
+		expectErr        bool
+	}
+
+	tests := map[string]testCase{
+		"valid": {
+			usernameTemplate: "V_{{.RoleName | uppercase}}",
+			version:          "19.0.0.0.0",
+			statement:        `CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+		},
+		"reserved word": {
+			usernameTemplate: "{{.RoleName}}",
+			version:          "19.0.0.0.0",
+			statement:        `CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+			expectErr:        true,
+		},
+		"reserved word quoted": {
+			usernameTemplate: "{{.RoleName}}",
+			version:          "19.0.0.0.0",
+			statement:        `CREATE USER "{{username}}" IDENTIFIED BY "{{password}}"`,
+		},
+		"long identifier on 19c": {
+			usernameTemplate: "V_{{.RoleName | uppercase}}_{{random 40}}",
+			version:          "19.0.0.0.0",
+			statement:        `CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+		},
+		"long identifier on 12.1": {
+			usernameTemplate: "V_{{.RoleName | uppercase}}_{{random 40}}",
+			version:          "12.1.0.2.0",
+			statement:        `CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+			expectErr:        true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"username_template": test.usernameTemplate,
+			})
+			fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+				return &fakeRows{
+					columns: []string{"VERSION"},
+					values:  [][]driver.Value{{test.version}},
+				}, nil
+			}
+
+			_, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+				UsernameConfig: dbplugin.UsernameMetadata{
+					DisplayName: "token",
+					RoleName:    "select",
+				},
+				Statements: dbplugin.Statements{
+					Commands: []string{test.statement},
+				},
+				Password: "98yq3thgnakjsfhjkl",
+			})
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if test.expectErr && len(fdb.statements()) > 0 {
+				t.Fatalf("no statements expected, got: %q", fdb.statements())
+			}
+		})
+	}
+}
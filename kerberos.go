@@ -0,0 +1,235 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+)
+
+const (
+	kerberosKeytabFile = "krb5.keytab"
+	kerberosConfFile   = "krb5.conf"
+	kerberosCacheFile  = "krb5cc"
+
+	// kerberosTicketRefresh is how long a ticket is used before a new one is obtained from the keytab. It is well
+	// below the usual ticket lifetime, so that new connections of the pool can always authenticate.
+	kerberosTicketRefresh = time.Hour
+
+	// redactedKerberosKeytab replaces the kerberos_keytab field in logs.
+	redactedKerberosKeytab = "[kerberos_keytab]"
+)
+
+// kerberosKeytabVersions are the first two bytes of the versions of the keytab format.
+var kerberosKeytabVersions = map[string]bool{
+	"\x05\x01": true,
+	"\x05\x02": true,
+}
+
+// kinitCommand obtains a ticket from a keytab. It is a variable so that tests can replace it.
+var kinitCommand = "kinit"
+
+// kerberosSettings are the kerberos_* fields of the config.
+type kerberosSettings struct {
+	keytab        []byte
+	encodedKeytab string
+	principal     string
+	krb5Conf      string
+}
+
+// kerberosTicket is a temporary directory holding the keytab of the root connection and the credential cache the
+// tickets obtained from it are stored in, which the connect descriptor points the Instant Client to.
+type kerberosTicket struct {
+	dir           string
+	principal     string
+	encodedKeytab string
+	hasConf       bool
+
+	lock     sync.Mutex
+	obtained time.Time
+}
+
+// parseKerberosSettings parses and validates the Kerberos settings of the config. The second return value reports
+// whether any are set.
+func parseKerberosSettings(config map[string]interface{}) (kerberosSettings, bool, error) {
+	fields := map[string]string{}
+	set := false
+	for _, name := range []string{"kerberos_keytab", "kerberos_principal", "kerberos_krb5_conf", "kerberos_realm"} {
+		value, err := strutil.GetString(config, name)
+		if err != nil {
+			return kerberosSettings{}, false, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		fields[name] = value
+		set = set || value != ""
+	}
+	if !set {
+		return kerberosSettings{}, false, nil
+	}
+	if !kerberosSupported {
+		return kerberosSettings{}, false, fmt.Errorf("Kerberos authentication is not supported by the %s driver", driverName)
+	}
+
+	s := kerberosSettings{
+		encodedKeytab: strings.TrimSpace(fields["kerberos_keytab"]),
+		principal:     strings.TrimSpace(fields["kerberos_principal"]),
+		krb5Conf:      fields["kerberos_krb5_conf"],
+	}
+
+	if s.encodedKeytab == "" {
+		return kerberosSettings{}, false, fmt.Errorf("kerberos_keytab is required to authenticate with Kerberos")
+	}
+	var err error
+	s.keytab, err = base64.StdEncoding.DecodeString(s.encodedKeytab)
+	if err != nil {
+		return kerberosSettings{}, false, fmt.Errorf("kerberos_keytab must be base64 encoded: %w", err)
+	}
+	if len(s.keytab) < 2 || !kerberosKeytabVersions[string(s.keytab[:2])] {
+		return kerberosSettings{}, false, fmt.Errorf("kerberos_keytab is not a keytab")
+	}
+
+	if s.principal == "" {
+		return kerberosSettings{}, false, fmt.Errorf("kerberos_principal is required to authenticate with Kerberos")
+	}
+	if strings.ContainsAny(s.principal, " \t\r\n\"'") || strings.HasPrefix(s.principal, "-") {
+		return kerberosSettings{}, false, fmt.Errorf("invalid kerberos_principal %q", s.principal)
+	}
+
+	// The realm completes the principal, and must match it if the principal already names one
+	if realm := strings.TrimSpace(fields["kerberos_realm"]); realm != "" {
+		err := validateConnectionField("kerberos_realm", realm)
+		if err != nil {
+			return kerberosSettings{}, false, err
+		}
+		_, principalRealm, ok := strings.Cut(s.principal, "@")
+		switch {
+		case !ok:
+			s.principal += "@" + realm
+		case principalRealm != realm:
+			return kerberosSettings{}, false, fmt.Errorf("kerberos_realm %q does not match the realm of kerberos_principal %q", realm, s.principal)
+		}
+	}
+	return s, true, nil
+}
+
+// validateKerberosConfig checks that no password is configured for the root connection, as it authenticates with
+// Kerberos instead.
+func validateKerberosConfig(config map[string]interface{}, connURL string) error {
+	password, err := strutil.GetString(config, "password")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve password: %w", err)
+	}
+	if password != "" {
+		return fmt.Errorf("password cannot be set with the kerberos_* fields")
+	}
+	if redactConnectionURL(connURL) != connURL {
+		return fmt.Errorf("connection_url cannot contain a password with the kerberos_* fields")
+	}
+	return nil
+}
+
+// writeTicketCache writes the keytab and configuration into a new temporary directory that only the plugin can
+// read, and obtains the first ticket.
+func (s kerberosSettings) writeTicketCache(ctx context.Context) (*kerberosTicket, error) {
+	// The directory is created with 0700 permissions
+	dir, err := os.MkdirTemp("", "vault-plugin-database-oracle-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kerberos directory: %w", err)
+	}
+	t := &kerberosTicket{
+		dir:           dir,
+		principal:     s.principal,
+		encodedKeytab: s.encodedKeytab,
+		hasConf:       s.krb5Conf != "",
+	}
+
+	files := map[string][]byte{
+		kerberosKeytabFile: s.keytab,
+	}
+	if t.hasConf {
+		files[kerberosConfFile] = []byte(s.krb5Conf)
+	}
+	for name, contents := range files {
+		err = os.WriteFile(filepath.Join(dir, name), contents, 0o600)
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	err = t.refresh(ctx, true)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return t, nil
+}
+
+// refresh obtains a new ticket from the keytab if the current one is older than kerberosTicketRefresh, or if
+// forced.
+func (t *kerberosTicket) refresh(ctx context.Context, force bool) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !force && time.Since(t.obtained) < kerberosTicketRefresh {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, kinitCommand,
+		"-k",
+		"-t", filepath.Join(t.dir, kerberosKeytabFile),
+		"-c", "FILE:"+filepath.Join(t.dir, kerberosCacheFile),
+		t.principal,
+	)
+	cmd.Env = os.Environ()
+	if t.hasConf {
+		cmd.Env = append(cmd.Env, "KRB5_CONFIG="+filepath.Join(t.dir, kerberosConfFile))
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to obtain a Kerberos ticket for %s: %w: %s", t.principal, err, strings.TrimSpace(string(out)))
+	}
+	t.obtained = time.Now()
+	return nil
+}
+
+// remove deletes the Kerberos directory, including the keytab and the tickets.
+func (t *kerberosTicket) remove() error {
+	if t == nil {
+		return nil
+	}
+	return os.RemoveAll(t.dir)
+}
+
+// kerberosURL returns the go-oci8 DSN connecting with external authentication as the principal of the ticket. The
+// credential cache and principal are set in the SECURITY section of the connect descriptor rather than in
+// sqlnet.ora, which the Instant Client reads for the whole process, so that each connection uses its own tickets.
+func kerberosURL(dsn string, t *kerberosTicket) (string, error) {
+	c, err := parseOCI8DSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	c.username, c.password = "", ""
+
+	descriptor := c.descriptor
+	if descriptor == "" {
+		descriptor = c.connectDescriptor(true)
+	}
+	c.descriptor, err = withSecurityParams(descriptor, map[string]string{
+		"KERBEROS5_CC_NAME":   filepath.Join(t.dir, kerberosCacheFile),
+		"KERBEROS5_PRINCIPAL": t.principal,
+	})
+	if err != nil {
+		return "", err
+	}
+	return "/@" + c.oci8DSN(), nil
+}
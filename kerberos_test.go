@@ -0,0 +1,388 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !goora
+
+package oracle
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// testKeytab is the header of a keytab, which is all the plugin checks.
+var testKeytab = base64.StdEncoding.EncodeToString([]byte{0x05, 0x02, 0x00, 0x00})
+
+// fakeKinit replaces kinit with a script that logs its arguments to the returned file and creates the credential
+// cache, or fails if fail is set.
+func fakeKinit(t *testing.T, fail bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "kinit.log")
+	script := `#!/bin/sh
+echo "KRB5_CONFIG=$KRB5_CONFIG $*" >> "` + log + `"
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-c" ]; then
+		shift
+		touch "${1#FILE:}"
+	fi
+	shift
+done
+`
+	if fail {
+		script = "#!/bin/sh\necho 'kinit: Preauthentication failed while getting initial credentials' >&2\nexit 1\n"
+	}
+	kinit := filepath.Join(dir, "kinit")
+	err := os.WriteFile(kinit, []byte(script), 0o700)
+	if err != nil {
+		t.Fatalf("failed to write kinit: %s", err)
+	}
+
+	original := kinitCommand
+	kinitCommand = kinit
+	t.Cleanup(func() {
+		kinitCommand = original
+	})
+	return log
+}
+
+func TestParseKerberosSettings(t *testing.T) {
+	type testCase struct {
+		config            map[string]interface{}
+		expectedSet       bool
+		expectedPrincipal string
+		expectErr         bool
+	}
+
+	tests := map[string]testCase{
+		"not set": {
+			config: map[string]interface{}{},
+		},
+		"principal with realm": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			expectedSet:       true,
+			expectedPrincipal: "vaultadmin@EXAMPLE.COM",
+		},
+		"realm field": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin",
+				"kerberos_realm":     "EXAMPLE.COM",
+			},
+			expectedSet:       true,
+			expectedPrincipal: "vaultadmin@EXAMPLE.COM",
+		},
+		"realm mismatch": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+				"kerberos_realm":     "OTHER.COM",
+			},
+			expectErr: true,
+		},
+		"missing keytab": {
+			config: map[string]interface{}{
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			expectErr: true,
+		},
+		"invalid base64": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    "not base64!",
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			expectErr: true,
+		},
+		"not a keytab": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    base64.StdEncoding.EncodeToString([]byte("keytab")),
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			expectErr: true,
+		},
+		"missing principal": {
+			config: map[string]interface{}{
+				"kerberos_keytab": testKeytab,
+			},
+			expectErr: true,
+		},
+		"option as principal": {
+			config: map[string]interface{}{
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "-V",
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, set, err := parseKerberosSettings(test.config)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if set != test.expectedSet {
+				t.Fatalf("Actual: %t\nExpected: %t", set, test.expectedSet)
+			}
+			if s.principal != test.expectedPrincipal {
+				t.Fatalf("Actual: %s\nExpected: %s", s.principal, test.expectedPrincipal)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeKerberos(t *testing.T) {
+	log := fakeKinit(t, false)
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	db := new()
+	req := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":     "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+			"username":           "VAULTADMIN",
+			"kerberos_keytab":    testKeytab,
+			"kerberos_principal": "vaultadmin",
+			"kerberos_realm":     "EXAMPLE.COM",
+			"kerberos_krb5_conf": "[libdefaults]\n\tdefault_realm = EXAMPLE.COM\n",
+		},
+		VerifyConnection: false,
+	}
+	_, err := db.Initialize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	if db.kerberos == nil || !strings.HasPrefix(db.kerberos.dir, tmpDir) {
+		t.Fatalf("expected a Kerberos directory in %s, got %#v", tmpDir, db.kerberos)
+	}
+	dir := db.kerberos.dir
+	expectedURL := "/@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db.example.com)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb))" +
+		"(SECURITY=(KERBEROS5_CC_NAME=" + filepath.Join(dir, kerberosCacheFile) + ")(KERBEROS5_PRINCIPAL=vaultadmin@EXAMPLE.COM)))"
+	if db.ConnectionURL != expectedURL {
+		t.Fatalf("Actual: %s\nExpected: %s", db.ConnectionURL, expectedURL)
+	}
+
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("expected kinit to be run: %s", err)
+	}
+	expected := "KRB5_CONFIG=" + filepath.Join(dir, kerberosConfFile) + " -k -t " + filepath.Join(dir, kerberosKeytabFile) + " -c FILE:" + filepath.Join(dir, kerberosCacheFile) + " vaultadmin@EXAMPLE.COM\n"
+	if string(calls) != expected {
+		t.Fatalf("Actual: %s\nExpected: %s", calls, expected)
+	}
+
+	// The ticket is only renewed once it is older than kerberosTicketRefresh
+	err = db.kerberos.refresh(context.Background(), false)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	db.kerberos.obtained = time.Now().Add(-kerberosTicketRefresh)
+	err = db.kerberos.refresh(context.Background(), false)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	calls, err = os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("failed to read kinit log: %s", err)
+	}
+	if strings.Count(string(calls), "\n") != 2 {
+		t.Fatalf("expected kinit to be run twice, got %s", calls)
+	}
+
+	if db.secretValues()[testKeytab] != redactedKerberosKeytab {
+		t.Fatalf("expected kerberos_keytab to be redacted, got %v", db.secretValues())
+	}
+	_, err = db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "VAULTADMIN",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "n3wPassw0rd",
+		},
+	})
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected the Kerberos directory to be removed, got: %v", err)
+	}
+}
+
+func TestOracle_InitializeKerberosConnections(t *testing.T) {
+	fakeKinit(t, false)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	// Each connection of the plugin process authenticates with its own tickets
+	var urls []string
+	for _, principal := range []string{"vaultadmin@EXAMPLE.COM", "vaultops@EXAMPLE.COM"} {
+		db := new()
+		defer db.Close()
+
+		req := dbplugin.InitializeRequest{
+			Config: map[string]interface{}{
+				"connection_url":     "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": principal,
+			},
+			VerifyConnection: false,
+		}
+		_, err := db.Initialize(context.Background(), req)
+		if err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+		if !strings.Contains(db.ConnectionURL, "(KERBEROS5_CC_NAME="+filepath.Join(db.kerberos.dir, kerberosCacheFile)+")") {
+			t.Fatalf("expected the connection to use its own credential cache, got %s", db.ConnectionURL)
+		}
+		urls = append(urls, db.ConnectionURL)
+	}
+	if urls[0] == urls[1] {
+		t.Fatalf("expected different connection URLs, got %s", urls[0])
+	}
+}
+
+func TestKerberosURL(t *testing.T) {
+	ticket := &kerberosTicket{
+		dir:       "/tmp/krb",
+		principal: "vaultadmin@EXAMPLE.COM",
+	}
+	security := "(SECURITY=(KERBEROS5_CC_NAME=/tmp/krb/krb5cc)(KERBEROS5_PRINCIPAL=vaultadmin@EXAMPLE.COM))"
+
+	type testCase struct {
+		dsn         string
+		expectedURL string
+		expectErr   bool
+	}
+
+	tests := map[string]testCase{
+		"easy connect": {
+			dsn:         "{{username}}/{{password}}@db.example.com:1521/orclpdb?as=sysdba",
+			expectedURL: "/@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db.example.com)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb))" + security + ")?as=SYSDBA",
+		},
+		"descriptor": {
+			dsn:         "{{username}}/{{password}}@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db.example.com)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=orclpdb))(SECURITY=(SSL_SERVER_DN_MATCH=TRUE)))",
+			expectedURL: "/@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db.example.com)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=orclpdb))(SECURITY=(SSL_SERVER_DN_MATCH=TRUE)(KERBEROS5_CC_NAME=/tmp/krb/krb5cc)(KERBEROS5_PRINCIPAL=vaultadmin@EXAMPLE.COM)))",
+		},
+		"descriptor list": {
+			dsn: "{{username}}/{{password}}@(DESCRIPTION_LIST=(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb)))" +
+				"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb))))",
+			expectedURL: "/@(DESCRIPTION_LIST=(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb))" + security + ")" +
+				"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orclpdb))" + security + "))",
+		},
+		"tns alias": {
+			dsn:       "{{username}}/{{password}}@orclpdb",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := kerberosURL(test.dsn, ticket)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expectedURL {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expectedURL)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializeKerberosFailure(t *testing.T) {
+	type testCase struct {
+		config     map[string]interface{}
+		failKinit  bool
+		errMessage string
+	}
+
+	tests := map[string]testCase{
+		"kinit fails": {
+			config: map[string]interface{}{
+				"connection_url":     "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			failKinit:  true,
+			errMessage: "Preauthentication failed",
+		},
+		"password": {
+			config: map[string]interface{}{
+				"connection_url":     "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+				"username":           "vaultadmin",
+				"password":           "r00tPassw0rd",
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			errMessage: "password cannot be set",
+		},
+		"password wallet": {
+			config: map[string]interface{}{
+				"connection_url":     "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+				"username":           "vaultadmin",
+				"password_wallet":    base64.StdEncoding.EncodeToString([]byte{0xa1, 0xf8, 0x4e, 0x36, 0x00}),
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			errMessage: "password_wallet is not supported",
+		},
+		"invalid connection_url": {
+			config: map[string]interface{}{
+				"connection_url":     "{{username}}/{{password}}@db1,,db2/orclpdb",
+				"kerberos_keytab":    testKeytab,
+				"kerberos_principal": "vaultadmin@EXAMPLE.COM",
+			},
+			errMessage: "invalid connection_url",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeKinit(t, test.failKinit)
+			tmpDir := t.TempDir()
+			t.Setenv("TMPDIR", tmpDir)
+
+			db := new()
+			defer db.Close()
+
+			req := dbplugin.InitializeRequest{
+				Config:           test.config,
+				VerifyConnection: false,
+			}
+			_, err := db.Initialize(context.Background(), req)
+			if err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !strings.Contains(err.Error(), test.errMessage) {
+				t.Fatalf("expected %q in the error, got: %s", test.errMessage, err)
+			}
+
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatalf("failed to read temporary directory: %s", err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("expected the Kerberos directory to be removed, got %d entries", len(entries))
+			}
+		})
+	}
+}
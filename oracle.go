@@ -8,59 +8,121 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
 	"github.com/hashicorp/vault/sdk/database/helper/connutil"
 	"github.com/hashicorp/vault/sdk/database/helper/dbutil"
 	"github.com/hashicorp/vault/sdk/helper/dbtxn"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/template"
-	_ "github.com/mattn/go-oci8"
 )
 
 const (
+	// oracleTypeName is reported as the type of the plugin regardless of the driver it is built with.
 	oracleTypeName = "oci8"
 
+	// parsedConnectionURLKey is the key of the normalized connection URL, with the password redacted, in the
+	// config returned by Initialize.
+	parsedConnectionURLKey = "parsed_connection_url"
+
 	defaultRotateCredsSql = `ALTER USER {{username}} IDENTIFIED BY "{{password}}"`
 
 	defaultUsernameTemplate = `{{ printf "V_%s_%s_%s_%s" (.DisplayName | truncate 8) (.RoleName | truncate 8) (random 20) (unix_time) | truncate 30 | uppercase | replace "-" "_" | replace "." "_" }}`
 )
 
-var (
-	defaultRevocationStatements = []string{
-		`REVOKE CONNECT FROM {{username}}`,
-		`REVOKE CREATE SESSION FROM {{username}}`,
-		`DROP USER {{username}}`,
-	}
+var defaultRevocationStatements = []string{
+	`REVOKE CONNECT FROM {{username}}`,
+	`REVOKE CREATE SESSION FROM {{username}}`,
+	`DROP USER {{username}}`,
+}
 
-	defaultSessionRevocationStatements = []string{
+// defaultSessionRevocationStatements returns the default revocation statements that also kill the sessions of
+// the user, for the given server version.
+func defaultSessionRevocationStatements(majorVersion int) []string {
+	return []string{
 		`ALTER USER {{username}} ACCOUNT LOCK`,
-		`begin
-		  for x in ( select inst_id, sid, serial# from gv$session where username="{{username}}" )
-		  loop
-		   execute immediate ( 'alter system kill session '''|| x.Sid || ',' || x.Serial# || '@' || x.inst_id ''' immediate' );
-		  end loop;
-		  dbms_lock.sleep(1);
-		end;`,
+		killSessionsStatement(majorVersion),
 		`DROP USER {{username}}`,
 	}
-)
+}
 
 var _ dbplugin.Database = (*Oracle)(nil)
 
 type Oracle struct {
 	*connutil.SQLConnectionProducer
 	usernameProducer template.StringTemplate
+	logger           hclog.Logger
+
+	// userLocks serialize operations on the same user. The lock of the embedded connection producer is only
+	// held while getting the connection, so that operations on different users run concurrently.
+	userLocks []*locksutil.LockEntry
+
+	// poolUsers is held for reading by the operations using the connection pool, after their user lock, and for
+	// writing by reconnect, so that the pool isn't closed while they use it.
+	poolUsers sync.RWMutex
+
+	// commonUsernameProducer generates the names of users created in the root container
+	// when no username_template is configured.
+	commonUsernameProducer  template.StringTemplate
+	defaultUsernameTemplate bool
+
+	// container is the container that statements are run in, unless overridden by the role.
+	container string
 
 	splitStatements    bool
 	disconnectSessions bool
+	expirationMode     string
+
+	expirationGracePeriod     time.Duration
+	sessionTerminationTimeout time.Duration
+	sessionTerminationMode    string
+
+	// passwordRolloverTime, if set, is how long the previous password of a static role remains valid after it is
+	// rotated, through the PASSWORD_ROLLOVER_TIME of passwordRolloverProfile.
+	passwordRolloverTime    time.Duration
+	passwordRolloverProfile string
+
+	// rolloverProfiles are the containers passwordRolloverProfile was set up in since Initialize.
+	rolloverProfilesLock sync.Mutex
+	rolloverProfiles     map[string]bool
+
+	// wallet is the temporary wallet directory written for the TLS settings. It is removed by Close.
+	wallet *tlsWallet
+
+	// passwordWallet holds the root credential when it is stored in the Secure External Password Store instead of
+	// the config, and kerberos the keytab and tickets when the root connection authenticates with Kerberos. They
+	// are removed by Close.
+	passwordWallet *passwordWallet
+	kerberos       *kerberosTicket
+
+	// rootConnectionURL is the translated connection URL with the {{username}} and {{password}} templates left
+	// in, so that the connection producer can be switched to a rotated root password.
+	rootConnectionURL string
+
+	// server describes the database server. It is detected by Initialize if the connection is verified,
+	// otherwise on first use, see getServerInfo.
+	serverLock     sync.Mutex
+	server         serverInfo
+	serverDetected bool
+	serverRetryAt  time.Time
 }
 
 func New() (interface{}, error) {
 	db := new()
+	// Logs written to stderr in JSON format are forwarded to the Vault server log
+	db.logger = hclog.New(&hclog.LoggerOptions{
+		Output:     os.Stderr,
+		JSONFormat: true,
+	})
+
 	// Wrap the plugin with middleware to sanitize errors
 	dbType := dbplugin.NewDatabaseErrorSanitizerMiddleware(db, db.secretValues)
 	return dbType, nil
@@ -68,24 +130,39 @@ func New() (interface{}, error) {
 
 func new() *Oracle {
 	connProducer := &connutil.SQLConnectionProducer{}
-	connProducer.Type = oracleTypeName
+	connProducer.Type = driverName
 
 	dbType := &Oracle{
 		SQLConnectionProducer: connProducer,
+		logger:                hclog.NewNullLogger(),
+		userLocks:             locksutil.CreateLocks(),
 	}
 
 	return dbType
 }
 
-func (o *Oracle) Initialize(ctx context.Context, req dbplugin.InitializeRequest) (dbplugin.InitializeResponse, error) {
+func (o *Oracle) Initialize(ctx context.Context, req dbplugin.InitializeRequest) (resp dbplugin.InitializeResponse, err error) {
 	usernameTemplate, err := strutil.GetString(req.Config, "username_template")
 	if err != nil {
 		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve username_template: %w", err)
 	}
-	if usernameTemplate == "" {
+	o.defaultUsernameTemplate = usernameTemplate == ""
+	if o.defaultUsernameTemplate {
 		usernameTemplate = defaultUsernameTemplate
 	}
 
+	container, err := strutil.GetString(req.Config, "container")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve container: %w", err)
+	}
+	if container != "" {
+		container, err = normalizeContainer(container)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+	}
+	o.container = container
+
 	splitStatements, err := coerceToBool(req.Config, "split_statements", true)
 	if err != nil {
 		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to parse 'split_statements' field: %w", err)
@@ -98,6 +175,84 @@ func (o *Oracle) Initialize(ctx context.Context, req dbplugin.InitializeRequest)
 	}
 	o.disconnectSessions = disconnectSessions
 
+	expirationMode, err := strutil.GetString(req.Config, "expiration_mode")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve expiration_mode: %w", err)
+	}
+	if expirationMode == "" {
+		expirationMode = expirationModeNone
+	}
+	if !expirationModes[expirationMode] {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid expiration_mode %q", expirationMode)
+	}
+	o.expirationMode = expirationMode
+
+	expirationGracePeriod, err := coerceToDuration(req.Config, "expiration_grace_period", defaultExpirationGracePeriod)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to parse 'expiration_grace_period' field: %w", err)
+	}
+	if expirationGracePeriod < 0 {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("'expiration_grace_period' must not be negative")
+	}
+	o.expirationGracePeriod = expirationGracePeriod
+
+	sessionTerminationTimeout, err := coerceToDuration(req.Config, "session_termination_timeout", defaultSessionTerminationTimeout)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to parse 'session_termination_timeout' field: %w", err)
+	}
+	if sessionTerminationTimeout < 0 {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("'session_termination_timeout' must not be negative")
+	}
+	o.sessionTerminationTimeout = sessionTerminationTimeout
+
+	sessionTerminationMode, err := strutil.GetString(req.Config, "session_termination_mode")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve session_termination_mode: %w", err)
+	}
+	if sessionTerminationMode == "" {
+		sessionTerminationMode = sessionTerminationKillImmediate
+	}
+	if !sessionTerminationModes[sessionTerminationMode] {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid session_termination_mode %q", sessionTerminationMode)
+	}
+	o.sessionTerminationMode = sessionTerminationMode
+
+	passwordRolloverTime, err := coerceToDuration(req.Config, "password_rollover_time", 0)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to parse 'password_rollover_time' field: %w", err)
+	}
+	err = validatePasswordRolloverTime(passwordRolloverTime)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+	o.passwordRolloverTime = passwordRolloverTime
+
+	passwordRolloverProfile, err := strutil.GetString(req.Config, "password_rollover_profile")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve password_rollover_profile: %w", err)
+	}
+	if passwordRolloverProfile == "" {
+		passwordRolloverProfile = defaultPasswordRolloverProfile
+	}
+	o.passwordRolloverProfile, err = normalizePasswordRolloverProfile(passwordRolloverProfile)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+	o.rolloverProfilesLock.Lock()
+	o.rolloverProfiles = nil
+	o.rolloverProfilesLock.Unlock()
+
+	passwordProfile, err := strutil.GetString(req.Config, "password_profile")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve password_profile: %w", err)
+	}
+	if passwordProfile != "" {
+		passwordProfile, err = normalizePasswordProfile(passwordProfile)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+	}
+
 	up, err := template.NewTemplate(template.Template(usernameTemplate))
 	if err != nil {
 		return dbplugin.InitializeResponse{}, fmt.Errorf("unable to initialize username template: %w", err)
@@ -109,12 +264,194 @@ func (o *Oracle) Initialize(ctx context.Context, req dbplugin.InitializeRequest)
 		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
 	}
 
-	err = o.SQLConnectionProducer.Initialize(ctx, req.Config, req.VerifyConnection)
+	if o.defaultUsernameTemplate {
+		o.commonUsernameProducer, err = template.NewTemplate(template.Template(defaultCommonUsernameTemplate))
+		if err != nil {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("unable to initialize common username template: %w", err)
+		}
+	}
+
+	connURL, err := strutil.GetString(req.Config, "connection_url")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve connection_url: %w", err)
+	}
+
+	fields, useFields, err := parseConnectionFields(req.Config)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+
+	tlsSettings, useTLS, err := parseTLSSettings(req.Config)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+	var wallet *tlsWallet
+	if useTLS {
+		wallet, err = tlsSettings.writeWallet()
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+	}
+	// The new wallet replaces the previous one once Initialize succeeds, and is removed if it doesn't
+	defer func() {
+		if err != nil {
+			wallet.remove()
+			return
+		}
+		if o.wallet != wallet {
+			o.wallet.remove()
+		}
+		o.wallet = wallet
+	}()
+
+	walletData, encodedWallet, useWallet, err := parsePasswordWallet(req.Config)
 	if err != nil {
 		return dbplugin.InitializeResponse{}, err
 	}
-	resp := dbplugin.InitializeResponse{
-		Config: req.Config,
+	kerberos, useKerberos, err := parseKerberosSettings(req.Config)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+	var pwWallet *passwordWallet
+	var ticket *kerberosTicket
+	switch {
+	case useWallet && useKerberos:
+		return dbplugin.InitializeResponse{}, fmt.Errorf("password_wallet cannot be combined with the kerberos_* fields")
+	case useWallet:
+		err = validatePasswordWalletConfig(req.Config, connURL, useTLS)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+		pwWallet, err = writePasswordWallet(walletData, encodedWallet)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+	case useKerberos:
+		err = validateKerberosConfig(req.Config, connURL)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+		ticket, err = kerberos.writeTicketCache(ctx)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, err
+		}
+	}
+	// Like the TLS wallet, the new password wallet or Kerberos directory replaces the previous one once Initialize
+	// succeeds
+	defer func() {
+		if err != nil {
+			pwWallet.remove()
+			ticket.remove()
+			return
+		}
+		if o.passwordWallet != pwWallet {
+			o.passwordWallet.remove()
+		}
+		if o.kerberos != ticket {
+			o.kerberos.remove()
+		}
+		o.passwordWallet = pwWallet
+		o.kerberos = ticket
+	}()
+
+	if useFields {
+		if connURL != "" {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("connection_url cannot be combined with %s", strings.Join(connectionFieldNames, ", "))
+		}
+		connURL, err = formatConnectionURL(fields, wallet)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("invalid connection fields: %w", err)
+		}
+	} else {
+		connURL, err = translateConnectionURL(connURL, wallet)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("invalid connection_url: %w", err)
+		}
+	}
+
+	switch {
+	case pwWallet != nil:
+		connURL = passwordWalletURL(connURL, pwWallet)
+	case ticket != nil:
+		connURL, err = kerberosURL(connURL, ticket)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("invalid connect string for Kerberos: %w", err)
+		}
+	}
+
+	// The connection producer is given a copy of the config so that the translated connection_url isn't
+	// returned to Vault and stored.
+	config := make(map[string]interface{}, len(req.Config))
+	for k, v := range req.Config {
+		config[k] = v
+	}
+	config["connection_url"] = connURL
+
+	if wallet != nil {
+		o.SQLConnectionProducer.Type = tlsDriverName
+	} else if o.SQLConnectionProducer.Type == tlsDriverName {
+		o.SQLConnectionProducer.Type = driverName
+	}
+
+	err = o.SQLConnectionProducer.Initialize(ctx, config, req.VerifyConnection)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+	o.rootConnectionURL = connURL
+
+	o.resetServerInfo()
+	server := serverInfo{}
+	var passwordRules *passwordProfileRules
+	if req.VerifyConnection {
+		o.poolUsers.RLock()
+		defer o.poolUsers.RUnlock()
+		db, err := o.getConnection(ctx)
+		if err != nil {
+			return dbplugin.InitializeResponse{}, fmt.Errorf("failed to get connection: %w", err)
+		}
+		server = o.getServerInfo(ctx, db)
+
+		if passwordProfile != "" {
+			rules, err := detectPasswordProfileRules(ctx, db, passwordProfile)
+			if err != nil {
+				return dbplugin.InitializeResponse{}, fmt.Errorf("failed to detect the rules of password_profile: %w", err)
+			}
+			if !rules.complexityKnown {
+				o.logger.Warn("the rules of the password verify function are unknown, check that the Vault password policy satisfies it",
+					"profile", rules.profile, "verify_function", rules.verifyFunction)
+			}
+			passwordRules = &rules
+		}
+	}
+
+	// The statements are not known yet, so the username is only checked against the rules that also apply
+	// to quoted identifiers
+	target := targetContainer(o.container, server)
+	sample, err := o.usernameProducerFor(target).Generate(sampleUsernameMetadata)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
+	}
+	err = validateUsername(sample, target, server.version, true)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
+	}
+	// Derived keys are added to a copy, leaving the config of the request untouched
+	respConfig := make(map[string]interface{}, len(req.Config))
+	for k, v := range req.Config {
+		respConfig[k] = v
+	}
+	resp = dbplugin.InitializeResponse{
+		Config: respConfig,
+	}
+	resp.SetSupportedCredentialTypes(supportedCredentialTypes)
+	if connURL != "" {
+		// connection_url is stored as written, the parsed form is only returned so that it can be reviewed
+		resp.Config[parsedConnectionURLKey] = redactConnectionURL(connURL)
+	}
+	// The rules of password_profile are only known if the connection was verified
+	delete(resp.Config, passwordProfileRulesKey)
+	if passwordRules != nil {
+		resp.Config[passwordProfileRulesKey] = passwordRules.config()
 	}
 	return resp, nil
 }
@@ -135,81 +472,233 @@ func coerceToBool(m map[string]interface{}, key string, def bool) (bool, error)
 	return false, fmt.Errorf("invalid type for key [%s]", key)
 }
 
-func (o *Oracle) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (dbplugin.NewUserResponse, error) {
-	o.Lock()
-	defer o.Unlock()
+func coerceToDuration(m map[string]interface{}, key string, def time.Duration) (time.Duration, error) {
+	rawVal, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	return parseutil.ParseDurationSecond(rawVal)
+}
 
-	username, err := o.usernameProducer.Generate(req.UsernameConfig)
+func (o *Oracle) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (resp dbplugin.NewUserResponse, err error) {
+	// Oracle errors may echo the creation statements, including the password
+	defer func() {
+		err = redactError(err, newUserSecrets(req)...)
+	}()
+
+	rs, err := o.prepareStatements(req.Statements.Commands)
 	if err != nil {
-		return dbplugin.NewUserResponse{}, fmt.Errorf("failed to generate username: %w", err)
+		return dbplugin.NewUserResponse{}, err
 	}
 
+	// The pool is released before taking the user lock, which reconnect may hold while waiting for the users of
+	// the pool
+	o.poolUsers.RLock()
 	db, err := o.getConnection(ctx)
+	var server serverInfo
+	if err == nil {
+		server = o.getServerInfo(ctx, db)
+	}
+	o.poolUsers.RUnlock()
 	if err != nil {
 		return dbplugin.NewUserResponse{}, fmt.Errorf("failed to get connection: %w", err)
 	}
+	container := targetContainer(rs.container, server)
+
+	username, err := o.usernameProducerFor(container).Generate(req.UsernameConfig)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, fmt.Errorf("failed to generate username: %w", err)
+	}
+
+	err = validateUsername(username, container, server.version, usernameQuoted(rs.creationStatements(req.CredentialType)))
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
 
-	err = o.newUser(ctx, db, username, req.Password, req.Expiration, req.Statements.Commands)
+	credential, err := credentialVariables(req)
 	if err != nil {
 		return dbplugin.NewUserResponse{}, err
 	}
 
-	resp := dbplugin.NewUserResponse{
+	lock := o.userLock(username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	o.poolUsers.RLock()
+	defer o.poolUsers.RUnlock()
+	db, err = o.getConnection(ctx)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	err = o.newUser(ctx, db, server, username, req.CredentialType, credential, req.Expiration, req.Statements.Commands)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	resp = dbplugin.NewUserResponse{
 		Username: username,
 	}
 	return resp, nil
 }
 
-func (o *Oracle) newUser(ctx context.Context, db *sql.DB, username, password string, expiration time.Time, commands []string) error {
-	tx, err := db.Begin()
+// newUser creates the user with the given type of credential, whose template variables are returned by
+// credentialVariables.
+func (o *Oracle) newUser(ctx context.Context, db *sql.DB, server serverInfo, username string, credentialType dbplugin.CredentialType, credential map[string]string, expiration time.Time, commands []string) error {
+	rs, err := o.prepareStatements(commands)
 	if err != nil {
-		return fmt.Errorf("failed to start a transaction: %w", err)
+		return err
 	}
-	// Effectively a no-op if the transaction commits successfully
-	defer tx.Rollback()
-
-	statements := o.parseStatements(commands)
+	statements := rs.creationStatements(credentialType)
 	if len(statements) == 0 {
 		return dbutil.ErrEmptyCreationStatement
 	}
 
-	for _, query := range statements {
-		m := map[string]string{
-			"username":   username,
-			"name":       username, // backwards compatibility
-			"password":   password,
-			"expiration": expiration.Format("2006-01-02 15:04:05-0700"),
+	return withContainer(ctx, db, rs.container, func(conn *sql.Conn) error {
+		// The job is scheduled before the user is created so that a partially created user is
+		// still cleaned up by the database. If the user doesn't exist when it runs, it does nothing.
+		if o.expirationMode != expirationModeNone && !expiration.IsZero() {
+			err := o.scheduleExpiration(ctx, conn, username, expiration)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = dbtxn.ExecuteTxQuery(ctx, tx, m, query)
+		tx, err := conn.BeginTx(ctx, nil)
 		if err != nil {
-			return fmt.Errorf("failed to execute query: %w", err)
+			return fmt.Errorf("failed to start a transaction: %w", err)
 		}
-	}
+		// Effectively a no-op if the transaction commits successfully
+		defer tx.Rollback()
+
+		for _, query := range statements {
+			m := map[string]string{
+				"username":   username,
+				"name":       username, // backwards compatibility
+				"expiration": expiration.Format(expirationFormat),
+			}
+			for k, v := range credential {
+				m[k] = v
+			}
+			m = rs.variables(m, server)
 
-	err = tx.Commit()
-	if err != nil {
-		return err
-	}
-	return nil
+			err = dbtxn.ExecuteTxQuery(ctx, tx, m, query)
+			if err != nil {
+				return fmt.Errorf("failed to execute query: %w", classifyError(err))
+			}
+		}
+
+		return tx.Commit()
+	})
 }
 
-func (o *Oracle) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+func (o *Oracle) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (resp dbplugin.UpdateUserResponse, err error) {
+	// Oracle errors may echo the rotation statements, including the new password
+	defer func() {
+		err = redactError(err, updateUserSecrets(req)...)
+	}()
+
 	if req.Password == nil && req.Expiration == nil {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no change requested")
 	}
 
+	rootUser := req.SelfManagedPassword == "" && o.isRootUser(req.Username)
+	if req.Password != nil && rootUser {
+		switch {
+		case o.passwordWallet != nil:
+			return dbplugin.UpdateUserResponse{}, fmt.Errorf("the password of %s is stored in password_wallet and cannot be rotated by Vault", req.Username)
+		case o.kerberos != nil:
+			return dbplugin.UpdateUserResponse{}, fmt.Errorf("%s authenticates with Kerberos and has no password to rotate", req.Username)
+		}
+	}
+
 	if req.Password != nil {
-		err := o.changeUserPassword(ctx, req.Username, req.Password.NewPassword, req.Password.Statements.Commands, req.SelfManagedPassword)
+		var err error
+		if rootUser {
+			err = o.rotateRootCredential(ctx, req.Password.NewPassword, req.Password.Statements.Commands)
+		} else {
+			err = o.changeUserPassword(ctx, req.Username, req.Password.NewPassword, req.Password.Statements.Commands, req.SelfManagedPassword)
+		}
 		if err != nil {
 			return dbplugin.UpdateUserResponse{}, fmt.Errorf("failed to change password: %w", err)
 		}
-		return dbplugin.UpdateUserResponse{}, nil
 	}
-	// Expiration change is a no-op
+
+	if req.Expiration != nil {
+		err := o.changeUserExpiration(ctx, req.Username, req.Expiration.NewExpiration, req.Expiration.Statements.Commands)
+		if err != nil {
+			return dbplugin.UpdateUserResponse{}, fmt.Errorf("failed to change expiration: %w", err)
+		}
+	}
 	return dbplugin.UpdateUserResponse{}, nil
 }
 
+// changeUserExpiration runs the renew statements, if any, and reschedules the expiration job of the user when
+// `expiration_mode` is enabled. Without either, an expiration change is a no-op.
+func (o *Oracle) changeUserExpiration(ctx context.Context, username string, expiration time.Time, renewStatements []string) error {
+	if username == "" {
+		return errors.New("must provide a username")
+	}
+
+	rs, err := o.prepareStatements(renewStatements)
+	if err != nil {
+		return err
+	}
+	if len(rs.statements) == 0 && o.expirationMode == expirationModeNone {
+		return nil
+	}
+
+	lock := o.userLock(username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	o.poolUsers.RLock()
+	defer o.poolUsers.RUnlock()
+	db, err := o.getConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get database connection: %w", err)
+	}
+	server := o.getServerInfo(ctx, db)
+	err = rs.resolveUserContainer(ctx, db, server, username)
+	if err != nil {
+		return err
+	}
+
+	return withContainer(ctx, db, rs.container, func(conn *sql.Conn) error {
+		if len(rs.statements) > 0 {
+			tx, err := conn.BeginTx(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("unable to create database transaction: %w", err)
+			}
+			// Effectively a no-op if the transaction commits successfully
+			defer tx.Rollback()
+
+			m := rs.variables(map[string]string{
+				"username":   username,
+				"name":       username, // backwards compatibility
+				"expiration": expiration.Format(expirationFormat),
+			}, server)
+			for _, query := range rs.statements {
+				err := dbtxn.ExecuteTxQuery(ctx, tx, m, query)
+				if err != nil {
+					return fmt.Errorf("unable to execute query [%s]: %w", query, classifyError(err))
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				return fmt.Errorf("unable to commit statements: %w", err)
+			}
+		}
+
+		if o.expirationMode != expirationModeNone {
+			return o.scheduleExpiration(ctx, conn, username, expiration)
+		}
+		return nil
+	})
+}
+
 func (o *Oracle) changeUserPassword(ctx context.Context, username string, newPassword string, rotateStatements []string, selfManagedPassword string) error {
 	if len(rotateStatements) == 0 {
 		rotateStatements = []string{defaultRotateCredsSql}
@@ -219,22 +708,21 @@ func (o *Oracle) changeUserPassword(ctx context.Context, username string, newPas
 		return errors.New("must provide both username and password")
 	}
 
-	variables := map[string]string{
-		"username": username,
-		"name":     username, // backwards compatibility
-		"password": newPassword,
+	rs, err := o.prepareStatements(rotateStatements)
+	if err != nil {
+		return err
 	}
-
-	statements := o.parseStatements(rotateStatements)
-	if len(statements) == 0 { // Extra check to protect against future changes
+	if len(rs.statements) == 0 { // Extra check to protect against future changes
 		return errors.New("no rotation statements found")
 	}
 
-	o.Lock()
-	defer o.Unlock()
+	lock := o.userLock(username)
+	lock.Lock()
+	defer lock.Unlock()
 
+	o.poolUsers.RLock()
+	defer o.poolUsers.RUnlock()
 	var db *sql.DB
-	var err error
 	if selfManagedPassword != "" {
 		db, err = o.getStaticConnection(ctx, username, selfManagedPassword)
 		if err != nil {
@@ -247,97 +735,270 @@ func (o *Oracle) changeUserPassword(ctx context.Context, username string, newPas
 		}
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("unable to create database transaction: %w", err)
+	server := o.getServerInfo(ctx, db)
+	variables := rs.variables(map[string]string{
+		"username": username,
+		"name":     username, // backwards compatibility
+		"password": newPassword,
+	}, server)
+
+	// A self-managed user rotates its own password, and can't change its profile
+	rollover := o.passwordRolloverTime
+	if selfManagedPassword != "" {
+		rollover = 0
 	}
-	// Effectively a no-op if the transaction commits successfully
-	defer tx.Rollback()
 
-	for _, query := range statements {
-		parsedQuery := dbutil.QueryHelper(query, variables)
-		err := dbtxn.ExecuteTxQuery(ctx, tx, nil, parsedQuery)
+	err = withContainer(ctx, db, rs.container, func(conn *sql.Conn) error {
+		if rollover > 0 {
+			assigned, err := o.assignPasswordRolloverProfile(ctx, conn, username, targetContainer(rs.container, server), server.version)
+			if err != nil {
+				return err
+			}
+			if !assigned {
+				rollover = 0
+			}
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
 		if err != nil {
-			return fmt.Errorf("unable to execute query [%s]: %w", query, err)
+			return fmt.Errorf("unable to create database transaction: %w", err)
+		}
+		// Effectively a no-op if the transaction commits successfully
+		defer tx.Rollback()
+
+		for _, query := range rs.statements {
+			parsedQuery := dbutil.QueryHelper(query, variables)
+			err := dbtxn.ExecuteTxQuery(ctx, tx, nil, parsedQuery)
+			if err != nil {
+				return fmt.Errorf("unable to execute query [%s]: %w", query, classifyError(err))
+			}
 		}
-	}
 
-	err = tx.Commit()
+		err = tx.Commit()
+		if err != nil {
+			return fmt.Errorf("unable to commit statements: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("unable to commit statements: %w", err)
+		return err
 	}
 
+	if rollover > 0 {
+		o.logPasswordRollover(username, rollover)
+	}
 	return nil
 }
 
 func (o *Oracle) DeleteUser(ctx context.Context, req dbplugin.DeleteUserRequest) (dbplugin.DeleteUserResponse, error) {
-	o.Lock()
-	defer o.Unlock()
+	lock := o.userLock(req.Username)
+	lock.Lock()
+	defer lock.Unlock()
 
+	o.poolUsers.RLock()
+	defer o.poolUsers.RUnlock()
 	db, err := o.getConnection(ctx)
 	if err != nil {
 		return dbplugin.DeleteUserResponse{}, fmt.Errorf("failed to make connection: %w", err)
 	}
 
-	tx, err := db.Begin()
+	rs, err := o.prepareStatements(req.Statements.Commands)
 	if err != nil {
-		return dbplugin.DeleteUserResponse{}, fmt.Errorf("failed to start transaction: %w", err)
+		return dbplugin.DeleteUserResponse{}, err
 	}
-	// Effectively a no-op if the transaction commits successfully
-	defer tx.Rollback()
+	server := o.getServerInfo(ctx, db)
+	err = rs.resolveUserContainer(ctx, db, server, req.Username)
+	if err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+
+	// Terminated sessions take a while to go away, so both waiting for them and dropping the user are retried
+	// until the same deadline.
+	deadline := o.sessionTerminationDeadline(ctx)
 
 	if o.disconnectSessions {
-		err = o.disconnectSession(db, req.Username)
+		terminated, err := o.disconnectSession(ctx, db, server, req.Username, rs.container, deadline)
+		for _, session := range terminated {
+			o.logger.Info("terminated session of revoked user", "username", req.Username, "mode", session.mode,
+				"inst_id", session.instID, "sid", session.sid, "serial#", session.serial, "machine", session.machine,
+				"program", session.program)
+		}
 		if err != nil {
 			return dbplugin.DeleteUserResponse{}, fmt.Errorf("failed to disconnect user %s: %w", req.Username, err)
 		}
 	}
 
-	revocationStatements := o.getRevocationStatements(req.Statements.Commands)
+	revocationStatements := o.getRevocationStatements(server, rs.statements)
 	if len(revocationStatements) == 0 {
 		return dbplugin.DeleteUserResponse{}, fmt.Errorf("empty revocation statements")
 	}
 
-	// We can't use a transaction here, because Oracle treats DROP USER as a DDL statement, which commits immediately.
-	for _, query := range revocationStatements {
-		m := map[string]string{
-			"username": req.Username,
-			"name":     req.Username, // backwards compatibility
+	err = withContainer(ctx, db, rs.container, func(conn *sql.Conn) error {
+		// A previous attempt to revoke the user may have dropped it already, in which case there is nothing left
+		// for the statements to do. If the check fails, the statements run anyway.
+		exists, err := userExists(ctx, conn, req.Username)
+		if err != nil {
+			o.logger.Warn("unable to check whether the user exists", "username", req.Username, "error", err)
 		}
+		if err == nil && !exists {
+			if o.expirationMode != expirationModeNone {
+				return o.cancelExpiration(ctx, conn, req.Username)
+			}
+			return nil
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %w", err)
+		}
+		// Effectively a no-op if the transaction commits successfully
+		defer tx.Rollback()
 
-		if err := dbtxn.ExecuteTxQuery(ctx, tx, m, query); err != nil {
-			return dbplugin.DeleteUserResponse{}, fmt.Errorf("failed to execute query: %w", err)
+		err = o.revokeProxyGrants(ctx, tx, req.Username, rs.proxyTarget)
+		if err != nil {
+			return err
 		}
+
+		// We can't use a transaction here, because Oracle treats DROP USER as a DDL statement, which commits immediately.
+		for _, query := range revocationStatements {
+			m := rs.variables(map[string]string{
+				"username": req.Username,
+				"name":     req.Username, // backwards compatibility
+			}, server)
+
+			err := retryUntil(ctx, deadline, func() (bool, error) {
+				err := classifyError(execRevocationStatement(ctx, tx, m, query, req.Username))
+				return errors.Is(err, ErrUserConnected), err
+			})
+			if isAlreadyRevoked(err) {
+				// The grant is already gone, e.g. because a previous attempt to revoke the user got partway
+				// through, so there is nothing left for this statement to do.
+				continue
+			}
+			if errors.Is(err, ErrUserNotFound) {
+				// The user may have been dropped meanwhile, e.g. by its expiration job. Otherwise the
+				// statement names another user or runs in the wrong container, which must not pass silently.
+				exists, existsErr := userExists(ctx, tx, req.Username)
+				if existsErr == nil && !exists {
+					break
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+		}
+
+		if o.expirationMode != expirationModeNone {
+			return o.cancelExpiration(ctx, conn, req.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return dbplugin.DeleteUserResponse{}, err
 	}
 
 	return dbplugin.DeleteUserResponse{}, nil
 }
 
-func (o *Oracle) getRevocationStatements(statements []string) []string {
+// getRevocationStatements returns the parsed revocation statements of the role, or the default statements if
+// there are none.
+func (o *Oracle) getRevocationStatements(server serverInfo, statements []string) []string {
 	if len(statements) > 0 {
-		statements = o.parseStatements(statements)
 		return statements
 	}
 
 	if !o.splitStatements || !o.disconnectSessions {
-		return defaultSessionRevocationStatements
+		return defaultSessionRevocationStatements(server.version.major)
 	} else {
 		return defaultRevocationStatements
 	}
 }
 
+// execRevocationStatement runs a revocation statement. The default block that kills sessions takes the username
+// as a bind variable, all other statements get it through the {{username}} template.
+func execRevocationStatement(ctx context.Context, tx *sql.Tx, m map[string]string, query, username string) error {
+	if isKillSessionsStatement(query) {
+		_, err := tx.ExecContext(ctx, query, username)
+		return err
+	}
+	return dbtxn.ExecuteTxQuery(ctx, tx, m, query)
+}
+
+// rowQueryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// userExists reports whether the user exists in the container the connection is in. ALL_USERS lists every user
+// regardless of the privileges of the Vault user.
+func userExists(ctx context.Context, db rowQueryer, username string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM all_users WHERE username IN (:1, UPPER(:2))`, username, username).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("unable to look up user %s: %w", username, classifyError(err))
+	}
+	return count > 0, nil
+}
+
+// storedUsername returns the name the user is stored under in the container the connection is in, which is in
+// upper case unless the user was created with a quoted name. A name stored exactly as given is preferred. The second
+// return value is false if the user doesn't exist.
+func storedUsername(ctx context.Context, db rowQueryer, username string) (string, bool, error) {
+	var name string
+	err := db.QueryRowContext(ctx, `SELECT username FROM all_users WHERE username IN (:1, UPPER(:2)) ORDER BY DECODE(username, :3, 0, 1)`, username, username, username).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("unable to look up user %s: %w", username, classifyError(err))
+	}
+	return name, true, nil
+}
+
+// Close closes the connection and removes the wallets written for the TLS settings and the password wallet.
+func (o *Oracle) Close() error {
+	err := o.SQLConnectionProducer.Close()
+
+	walletErr := o.wallet.remove()
+	o.wallet = nil
+	if err == nil && walletErr != nil {
+		err = fmt.Errorf("failed to remove wallet: %w", walletErr)
+	}
+
+	walletErr = o.passwordWallet.remove()
+	o.passwordWallet = nil
+	if err == nil && walletErr != nil {
+		err = fmt.Errorf("failed to remove password wallet: %w", walletErr)
+	}
+	walletErr = o.kerberos.remove()
+	o.kerberos = nil
+	if err == nil && walletErr != nil {
+		err = fmt.Errorf("failed to remove Kerberos directory: %w", walletErr)
+	}
+	return err
+}
+
 func (o *Oracle) Type() (string, error) {
 	return oracleTypeName, nil
 }
 
 func (o *Oracle) secretValues() map[string]string {
-	return map[string]string{
-		o.Password: "[password]",
+	secrets := map[string]string{}
+	for _, secret := range secretForms(o.Password) {
+		secrets[secret] = redactedPassword
+	}
+	if o.passwordWallet != nil {
+		secrets[o.passwordWallet.encoded] = redactedPasswordWallet
 	}
+	if o.kerberos != nil {
+		secrets[o.kerberos.encodedKeytab] = redactedKerberosKeytab
+	}
+	return secrets
 }
 
-// parseStatements conditionally splits the list of commands on semi-colons. If `split_statements` is true, this
-// will return the provided slice of commands without altering them
+// parseStatements conditionally splits the list of commands into individual statements. If `split_statements` is
+// false, this will return the provided slice of commands without altering them other than trimming whitespace and
+// removing empty commands. Otherwise each command is split with splitStatements, which keeps PL/SQL blocks intact.
 func (o *Oracle) parseStatements(rawStatements []string) []string {
 	if !o.splitStatements {
 		statements := []string{}
@@ -353,104 +1014,248 @@ func (o *Oracle) parseStatements(rawStatements []string) []string {
 
 	statements := []string{}
 	for _, rawQ := range rawStatements {
-		split := strutil.ParseArbitraryStringSlice(rawQ, ";")
-		for _, newQ := range split {
-			newQ = strings.TrimSpace(newQ)
-			if newQ == "" {
-				continue
-			}
-			statements = append(statements, newQ)
-		}
+		statements = append(statements, splitStatements(rawQ)...)
 	}
 	return statements
 }
 
-func (o *Oracle) disconnectSession(db *sql.DB, username string) error {
-	err := o.disconnectFromCluster(db, username)
-	if err == nil {
-		return nil
+// roleStatements are the parsed statements of a role, with its DEFINE commands removed.
+type roleStatements struct {
+	statements []string
+
+	// defines are the values of the DEFINE commands, which are available as template variables.
+	defines map[string]string
+
+	// container is the container to run the statements in, either set by `DEFINE container = <name>` or
+	// the configured container.
+	container string
+
+	// proxyTarget is the schema that users of the role are allowed to connect through to, set by
+	// `DEFINE proxy_target = <schema>`, and proxyRoles the roles set by `DEFINE proxy_roles = <role>, ...`
+	// that the proxy session is limited to.
+	proxyTarget string
+	proxyRoles  []string
+}
+
+// prepareStatements parses the statements of a role and extracts its DEFINE commands.
+func (o *Oracle) prepareStatements(rawStatements []string) (roleStatements, error) {
+	defines, statements, err := extractDefines(o.parseStatements(rawStatements))
+	if err != nil {
+		return roleStatements{}, err
+	}
+
+	rs := roleStatements{
+		statements: statements,
+		defines:    defines,
+		container:  o.container,
+	}
+	if container, ok := defines["container"]; ok {
+		rs.container, err = normalizeContainer(container)
+		if err != nil {
+			return roleStatements{}, err
+		}
+	}
+	if target, ok := defines["proxy_target"]; ok {
+		rs.proxyTarget, err = normalizeProxyTarget(target)
+		if err != nil {
+			return roleStatements{}, err
+		}
+	}
+	if roles, ok := defines["proxy_roles"]; ok {
+		if rs.proxyTarget == "" {
+			return roleStatements{}, fmt.Errorf("proxy_roles requires a proxy_target")
+		}
+		rs.proxyRoles, err = parseProxyRoles(roles)
+		if err != nil {
+			return roleStatements{}, err
+		}
 	}
+	return rs, nil
+}
 
-	return o.disconnectLocal(db, username)
+// variables returns the template variables of the statements: the given variables of the operation, the version
+// of the server, the container the statements run in, the proxy target and roles, and the DEFINE commands of the
+// role, which don't override any of the others.
+func (rs roleStatements) variables(vars map[string]string, server serverInfo) map[string]string {
+	vars["server_version"] = server.version.String()
+	vars["container"] = targetContainer(rs.container, server)
+	vars["proxy_target"] = rs.proxyTarget
+	vars["proxy_roles"] = strings.Join(rs.proxyRoles, ", ")
+	return mergeDefines(vars, rs.defines)
 }
 
-func (o *Oracle) disconnectFromCluster(db *sql.DB, username string) error {
-	disconnectVars := map[string]string{
-		"username": username,
+// terminatedSession is a session that was terminated when revoking a user.
+type terminatedSession struct {
+	instID  int
+	sid     int
+	serial  int
+	machine string
+	program string
+
+	// mode is how the session was terminated, see sessionTerminationModes.
+	mode string
+}
+
+// disconnectSession terminates the sessions of the user according to session_termination_mode and waits for them
+// to end. If a container is given, only the sessions in that container are terminated, since a local user of the
+// same name may exist in other pluggable databases. The sessions that were terminated are returned even if an
+// error occurs partway through.
+func (o *Oracle) disconnectSession(ctx context.Context, db *sql.DB, server serverInfo, username, container string, deadline time.Time) ([]terminatedSession, error) {
+	filter := sessionFilter{
+		username: username,
+	}
+	if container != "" {
+		conID, err := containerID(ctx, db, container)
+		if err != nil {
+			return nil, err
+		}
+		filter.conID = conID
 	}
-	query := dbutil.QueryHelper(`SELECT inst_id, sid, serial#, username FROM gv$session WHERE username = UPPER('{{username}}')`, disconnectVars)
 
-	disconnectStmt, err := db.Prepare(query)
+	mode := o.sessionTerminationMode
+	if mode == sessionTerminationLockThenWait {
+		err := o.drainSessions(ctx, db, server, container, filter, deadline)
+		if err != nil {
+			return nil, err
+		}
+		// The sessions that didn't end by themselves are killed
+		mode = sessionTerminationKillImmediate
+	}
+
+	// gv$session is only used on RAC if the server is known, since terminating the sessions on the local
+	// instance only wouldn't disconnect the user
+	var terminated []terminatedSession
+	var err error
+	switch {
+	case !server.racKnown:
+		terminated, err = o.disconnectFromCluster(db, filter, mode)
+		if err != nil {
+			o.logger.Warn("unable to terminate sessions on all instances, falling back to the local instance",
+				"username", username, "error", err)
+			var local []terminatedSession
+			local, err = o.disconnectLocal(db, filter, mode)
+			terminated = append(terminated, local...)
+		}
+	case server.rac:
+		terminated, err = o.disconnectFromCluster(db, filter, mode)
+	default:
+		terminated, err = o.disconnectLocal(db, filter, mode)
+	}
 	if err != nil {
-		return err
+		return terminated, err
+	}
+
+	return terminated, o.waitForSessions(ctx, db, server, filter, deadline)
+}
+
+func (o *Oracle) disconnectFromCluster(db *sql.DB, filter sessionFilter, mode string) ([]terminatedSession, error) {
+	where, args := filter.where()
+	disconnectStmt, err := db.Prepare(`SELECT inst_id, sid, serial#, machine, program FROM gv$session WHERE ` + where)
+	if err != nil {
+		return nil, err
 	}
 	defer disconnectStmt.Close()
-	rows, err := disconnectStmt.Query()
+	rows, err := disconnectStmt.Query(args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
 
+	var terminated []terminatedSession
 	for rows.Next() {
-		var instID, sessionID, serialNumber int
-		var username sql.NullString
-		err = rows.Scan(&instID, &sessionID, &serialNumber, &username)
+		session := terminatedSession{mode: mode}
+		var machine, program sql.NullString
+		err = rows.Scan(&session.instID, &session.sid, &session.serial, &machine, &program)
 		if err != nil {
-			return err
+			return terminated, err
 		}
+		session.machine, session.program = machine.String, program.String
 
-		killStatement := fmt.Sprintf(`ALTER SYSTEM KILL SESSION '%d,%d,@%d' IMMEDIATE`, sessionID, serialNumber, instID)
-		_, err = db.Exec(killStatement)
-		if err != nil {
-			return err
+		_, err = db.Exec(terminateSessionStatement(session, mode, true))
+		err = classifyError(err)
+		if errors.Is(err, ErrSessionNotFound) {
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrSessionMarkedForKill) {
+			return terminated, err
 		}
+		terminated = append(terminated, session)
 	}
 	err = rows.Err()
 	if err != nil {
-		return err
+		return terminated, err
 	}
-	return nil
+	return terminated, nil
 }
 
-func (o *Oracle) disconnectLocal(db *sql.DB, username string) error {
-	disconnectVars := map[string]string{
-		"username": username,
-	}
-	query := dbutil.QueryHelper(`SELECT sid, serial#, username FROM v$session WHERE username = UPPER('{{username}}')`, disconnectVars)
-
-	disconnectStmt, err := db.Prepare(query)
+func (o *Oracle) disconnectLocal(db *sql.DB, filter sessionFilter, mode string) ([]terminatedSession, error) {
+	where, args := filter.where()
+	disconnectStmt, err := db.Prepare(`SELECT SYS_CONTEXT('USERENV', 'INSTANCE'), sid, serial#, machine, program FROM v$session WHERE ` + where)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer disconnectStmt.Close()
-	rows, err := disconnectStmt.Query()
+	rows, err := disconnectStmt.Query(args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
 
+	var terminated []terminatedSession
 	for rows.Next() {
-		var sessionID, serialNumber int
-		var username sql.NullString
-		err = rows.Scan(&sessionID, &serialNumber, &username)
+		session := terminatedSession{mode: mode}
+		var machine, program sql.NullString
+		err = rows.Scan(&session.instID, &session.sid, &session.serial, &machine, &program)
 		if err != nil {
-			return err
+			return terminated, err
 		}
+		session.machine, session.program = machine.String, program.String
 
-		killStatement := fmt.Sprintf(`ALTER SYSTEM KILL SESSION '%d,%d' IMMEDIATE`, sessionID, serialNumber)
-		_, err = db.Exec(killStatement)
-		if err != nil {
-			return err
+		_, err = db.Exec(terminateSessionStatement(session, mode, false))
+		err = classifyError(err)
+		if errors.Is(err, ErrSessionNotFound) {
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrSessionMarkedForKill) {
+			return terminated, err
 		}
+		terminated = append(terminated, session)
 	}
 	err = rows.Err()
 	if err != nil {
-		return err
+		return terminated, err
 	}
-	return nil
+	return terminated, nil
+}
+
+// usernameProducerFor returns the producer of the names of users created in the given container.
+func (o *Oracle) usernameProducerFor(container string) template.StringTemplate {
+	if container == rootContainer && o.defaultUsernameTemplate {
+		return o.commonUsernameProducer
+	}
+	return o.usernameProducer
+}
+
+// userLock returns the lock for operations on the given user. Unquoted identifiers are case-insensitive, so
+// the name is uppercased the way Oracle stores it.
+func (o *Oracle) userLock(username string) *locksutil.LockEntry {
+	return locksutil.LockForKey(o.userLocks, strings.ToUpper(username))
 }
 
+// getConnection returns the connection pool, (re)connecting if needed. The pool itself is safe for concurrent
+// use, so the connection producer is only locked while getting it.
 func (o *Oracle) getConnection(ctx context.Context) (*sql.DB, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	// New connections of the pool authenticate with the ticket in the cache, so it is renewed before it expires
+	if o.kerberos != nil {
+		err := o.kerberos.refresh(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := o.Connection(ctx)
 	if err != nil {
 		return nil, err
@@ -460,6 +1265,9 @@ func (o *Oracle) getConnection(ctx context.Context) (*sql.DB, error) {
 }
 
 func (o *Oracle) getStaticConnection(ctx context.Context, username, password string) (*sql.DB, error) {
+	o.Lock()
+	defer o.Unlock()
+
 	db, err := o.StaticConnection(ctx, username, password)
 	if err != nil {
 		return nil, err
@@ -6,11 +6,13 @@ package oracle
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"os"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -78,7 +80,7 @@ func prepareOracleTestContainer(t *testing.T) (connString string, cleanup func()
 	if err = pool.Retry(func() error {
 		var err error
 		var db *sql.DB
-		db, err = sql.Open("oci8", connString)
+		db, err = openDB(connString)
 		if err != nil {
 			return err
 		}
@@ -97,8 +99,17 @@ func TestOracle_Initialize(t *testing.T) {
 	db := new()
 	defer dbtesting.AssertClose(t, db)
 
+	parsedConnURL, err := translateConnectionURL(connURL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
 	expectedConfig := map[string]interface{}{
-		"connection_url": connURL,
+		"connection_url":       connURL,
+		parsedConnectionURLKey: redactConnectionURL(parsedConnURL),
+		dbplugin.SupportedCredentialTypesKey: []interface{}{
+			dbplugin.CredentialTypePassword.String(),
+			dbplugin.CredentialTypeClientCertificate.String(),
+		},
 	}
 	req := dbplugin.InitializeRequest{
 		Config: map[string]interface{}{
@@ -458,6 +469,23 @@ func TestParseStatements(t *testing.T) {
 				"quuz",
 			},
 		},
+		"PL/SQL block": {
+			splitStatements: true,
+			input: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}";
+				BEGIN
+					EXECUTE IMMEDIATE 'GRANT CONNECT TO {{username}}';
+				END;
+				GRANT CREATE SESSION TO {{username}};`,
+			},
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+				`BEGIN
+					EXECUTE IMMEDIATE 'GRANT CONNECT TO {{username}}';
+				END;`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+		},
 		"do not split statements": {
 			splitStatements: false,
 			input: []string{
@@ -579,7 +607,7 @@ func TestUpdateUser_ChangePassword(t *testing.T) {
 				`CREATE USER "{{username}}" IDENTIFIED BY "{{password}}"`,
 				`GRANT ALL PRIVILEGES TO {{username}}`,
 			}
-			err = db.newUser(ctx, sqlDB, username, initialPassword, time.Now().Add(1*time.Minute), createCommands)
+			err = db.newUser(ctx, sqlDB, db.getServerInfo(ctx, sqlDB), username, dbplugin.CredentialTypePassword, map[string]string{"password": initialPassword}, time.Now().Add(1*time.Minute), createCommands)
 			if err != nil {
 				t.Fatalf("failed to create user: %s", err)
 			}
@@ -648,7 +676,7 @@ func TestDisconnectSession(t *testing.T) {
 	}
 
 	// Establish connection
-	conn, err := sql.Open("oci8", userURL)
+	conn, err := openDB(userURL)
 	if err != nil {
 		t.Fatalf("Failed to open initial connection: %s", err)
 	}
@@ -675,6 +703,155 @@ func TestDisconnectSession(t *testing.T) {
 	}
 }
 
+func TestOracle_UserLocks(t *testing.T) {
+	newUserReq := func(displayName string) dbplugin.NewUserRequest {
+		return dbplugin.NewUserRequest{
+			UsernameConfig: dbplugin.UsernameMetadata{
+				DisplayName: displayName,
+				RoleName:    "test",
+			},
+			Statements: dbplugin.Statements{
+				Commands: []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+			},
+			Password:   "98yq3thgnakjsfhjkl",
+			Expiration: time.Now().Add(time.Minute),
+		}
+	}
+
+	// blockDrop makes DROP USER of the given user hang until the returned function is called.
+	blockDrop := func(fdb *fakeDB, username string) (blocked <-chan struct{}, release func()) {
+		blockedCh := make(chan struct{})
+		releaseCh := make(chan struct{})
+		fdb.exec = func(query string, _ []driver.Value) error {
+			if query == "DROP USER "+username {
+				close(blockedCh)
+				<-releaseCh
+			}
+			return nil
+		}
+		return blockedCh, func() { close(releaseCh) }
+	}
+
+	// Usernames derived from the display name only, as random ones may share a lock with V_SLOW
+	config := map[string]interface{}{
+		"disconnect_sessions":         false,
+		"session_termination_timeout": "0s",
+		"username_template":           "V_{{.DisplayName | uppercase}}",
+	}
+
+	t.Run("different users run concurrently", func(t *testing.T) {
+		db, fdb := newFakeOracle(t, config)
+		if db.userLock("V_OTHER") == db.userLock("V_SLOW") {
+			t.Fatalf("expected V_OTHER and V_SLOW to have different locks")
+		}
+		blocked, release := blockDrop(fdb, "V_SLOW")
+
+		deleteErr := make(chan error, 1)
+		go func() {
+			_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{Username: "V_SLOW"})
+			deleteErr <- err
+		}()
+		<-blocked
+
+		newUserErr := make(chan error, 1)
+		go func() {
+			_, err := db.NewUser(context.Background(), newUserReq("other"))
+			newUserErr <- err
+		}()
+
+		select {
+		case err := <-newUserErr:
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("NewUser was blocked by the revocation of another user")
+		}
+
+		release()
+		if err := <-deleteErr; err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+	})
+
+	t.Run("same user is serialized", func(t *testing.T) {
+		db, fdb := newFakeOracle(t, config)
+		blocked, release := blockDrop(fdb, "V_SLOW")
+
+		deleteErr := make(chan error, 1)
+		go func() {
+			_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{Username: "V_SLOW"})
+			deleteErr <- err
+		}()
+		<-blocked
+
+		updateErr := make(chan error, 1)
+		go func() {
+			_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+				Username: "v_slow",
+				Password: &dbplugin.ChangePassword{NewPassword: "n3wPassw0rd"},
+			})
+			updateErr <- err
+		}()
+
+		select {
+		case <-updateErr:
+			t.Fatalf("UpdateUser ran while the same user was being revoked")
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		release()
+		if err := <-deleteErr; err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+		if err := <-updateErr; err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+
+		statements := fdb.statements()
+		last := statements[len(statements)-1]
+		if !strings.HasPrefix(last, "ALTER USER v_slow IDENTIFIED BY") {
+			t.Fatalf("password was not changed after the revocation: %q", statements)
+		}
+	})
+
+	t.Run("concurrent operations", func(t *testing.T) {
+		db, _ := newFakeOracle(t, config)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 3*20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				resp, err := db.NewUser(context.Background(), newUserReq(fmt.Sprintf("user%d", i)))
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+					Username: resp.Username,
+					Password: &dbplugin.ChangePassword{NewPassword: "n3wPassw0rd"},
+				})
+				if err != nil {
+					errs <- err
+				}
+				_, err = db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{Username: resp.Username})
+				if err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+	})
+}
+
 func getNewConnStr(connString, username, password string) (string, error) {
 	splitStr := strings.Split(connString, "@")
 	if len(splitStr) != 2 {
@@ -683,6 +860,15 @@ func getNewConnStr(connString, username, password string) (string, error) {
 	return fmt.Sprintf("%s/%s@%s", username, password, splitStr[1]), nil
 }
 
+// openDB opens a connection with the driver the plugin is built with.
+func openDB(connString string) (*sql.DB, error) {
+	connURL, err := translateConnectionURL(connString, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Open(driverName, connURL)
+}
+
 func testCredentialsExist(connString, username, password string) error {
 	connURL, err := getNewConnStr(connString, username, password)
 	if err != nil {
@@ -690,7 +876,7 @@ func testCredentialsExist(connString, username, password string) error {
 	}
 
 	// Log in with the new credentials
-	db, err := sql.Open("oci8", connURL)
+	db, err := openDB(connURL)
 	if err != nil {
 		return err
 	}
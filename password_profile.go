@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const (
+	// passwordProfileRulesKey is the key of the password rules of `password_profile` in the config returned by
+	// Initialize.
+	passwordProfileRulesKey = "password_profile_rules"
+
+	// defaultProfile is the profile of users created without one, and the profile other profiles inherit
+	// DEFAULT limits from.
+	defaultProfile = "DEFAULT"
+
+	// maxGeneratedPasswordLength is the longest password that can be set with IDENTIFIED BY before 23ai.
+	maxGeneratedPasswordLength = 30
+
+	// defaultGeneratedPasswordLength is the length of the passwords generated by Vault's default password policy.
+	defaultGeneratedPasswordLength = 20
+
+	// passwordSpecialChars are the special characters suggested for passwords. They are accepted by every verify
+	// function shipped with Oracle, and need no escaping in statements or connection URLs beyond URL escaping.
+	passwordSpecialChars = "!#$%*+-:=?_"
+)
+
+// passwordComplexity are the minimum numbers of characters of each kind a verify function requires.
+type passwordComplexity struct {
+	length    int
+	letters   int
+	uppercase int
+	lowercase int
+	digits    int
+	special   int
+}
+
+// verifyFunctionComplexity are the rules of the verify functions created by utlpwdmg.sql. Besides these, they
+// reject passwords containing the username or the server name, or too similar to the previous password, which
+// random passwords don't run into.
+var verifyFunctionComplexity = map[string]passwordComplexity{
+	"VERIFY_FUNCTION":               {length: 4, letters: 1, digits: 1, special: 1},
+	"VERIFY_FUNCTION_11G":           {length: 8, letters: 1, digits: 1},
+	"ORA12C_VERIFY_FUNCTION":        {length: 8, letters: 1, digits: 1},
+	"ORA12C_STRONG_VERIFY_FUNCTION": {length: 9, uppercase: 2, lowercase: 2, digits: 2, special: 2},
+	"ORA12C_STIG_VERIFY_FUNCTION":   {length: 15, uppercase: 1, lowercase: 1, digits: 1, special: 1},
+}
+
+// passwordProfileRules are the password rules of a profile.
+type passwordProfileRules struct {
+	profile        string
+	verifyFunction string
+
+	// complexity is only known for the verify functions shipped with Oracle.
+	complexity      passwordComplexity
+	complexityKnown bool
+}
+
+// normalizePasswordProfile validates the name of `password_profile` and returns it in upper case. DEFAULT is a
+// reserved word, but also the name of the profile users get by default.
+func normalizePasswordProfile(profile string) (string, error) {
+	if strings.EqualFold(profile, defaultProfile) {
+		return defaultProfile, nil
+	}
+	err := validateIdentifier(profile, maxIdentifierBytes, false)
+	if err != nil {
+		return "", fmt.Errorf("invalid password_profile %q: %w", profile, err)
+	}
+	return strings.ToUpper(profile), nil
+}
+
+// detectPasswordProfileRules looks up the PASSWORD_VERIFY_FUNCTION of the profile, following DEFAULT to the
+// DEFAULT profile. It requires SELECT on DBA_PROFILES.
+func detectPasswordProfileRules(ctx context.Context, db *sql.DB, profile string) (passwordProfileRules, error) {
+	verifyFunction, err := profileVerifyFunction(ctx, db, profile)
+	if err != nil {
+		return passwordProfileRules{}, err
+	}
+	if verifyFunction == defaultProfile && profile != defaultProfile {
+		verifyFunction, err = profileVerifyFunction(ctx, db, defaultProfile)
+		if err != nil {
+			return passwordProfileRules{}, err
+		}
+	}
+
+	rules := passwordProfileRules{
+		profile: profile,
+	}
+	switch verifyFunction {
+	case "", "NULL":
+		rules.complexityKnown = true
+	default:
+		rules.verifyFunction = verifyFunction
+		rules.complexity, rules.complexityKnown = verifyFunctionComplexity[verifyFunction]
+	}
+	return rules, nil
+}
+
+func profileVerifyFunction(ctx context.Context, db *sql.DB, profile string) (string, error) {
+	var limit string
+	err := db.QueryRowContext(ctx, `SELECT "LIMIT" FROM dba_profiles WHERE profile = :1 AND resource_name = 'PASSWORD_VERIFY_FUNCTION'`, profile).Scan(&limit)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("profile %s does not exist", profile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to query profile %s: %w", profile, classifyError(err))
+	}
+	return strings.ToUpper(strings.TrimSpace(limit)), nil
+}
+
+// config returns the rules as they are published in the config returned by Initialize. The Vault password policy
+// is only suggested if the rules of the verify function are known.
+func (r passwordProfileRules) config() map[string]interface{} {
+	config := map[string]interface{}{
+		"profile":         r.profile,
+		"verify_function": r.verifyFunction,
+	}
+	if !r.complexityKnown {
+		return config
+	}
+	config["min_length"] = r.complexity.length
+	config["min_letters"] = r.complexity.letters
+	config["min_uppercase"] = r.complexity.uppercase
+	config["min_lowercase"] = r.complexity.lowercase
+	config["min_digits"] = r.complexity.digits
+	config["min_special"] = r.complexity.special
+	config["password_policy"] = r.complexity.passwordPolicy()
+	return config
+}
+
+// passwordPolicy returns a Vault password policy generating passwords that satisfy the complexity rules.
+func (c passwordComplexity) passwordPolicy() string {
+	length := defaultGeneratedPasswordLength
+	if c.length > length {
+		length = min(c.length, maxGeneratedPasswordLength)
+	}
+
+	lowercase := "abcdefghijklmnopqrstuvwxyz"
+	uppercase := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits := "0123456789"
+
+	var policy strings.Builder
+	fmt.Fprintf(&policy, "length = %d\n", length)
+	rule := func(charset string, minChars int) {
+		fmt.Fprintf(&policy, "\nrule \"charset\" {\n  charset = %q\n  min-chars = %d\n}\n", charset, minChars)
+	}
+	// Letters required regardless of case are covered by the lowercase letters
+	rule(lowercase, max(c.lowercase, c.letters-c.uppercase, 1))
+	rule(uppercase, max(c.uppercase, 1))
+	rule(digits, max(c.digits, 1))
+	if c.special > 0 {
+		rule(passwordSpecialChars, c.special)
+	}
+	return policy.String()
+}
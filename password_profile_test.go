@@ -0,0 +1,225 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestPasswordComplexityPolicy(t *testing.T) {
+	type testCase struct {
+		complexity passwordComplexity
+		expected   string
+	}
+
+	tests := map[string]testCase{
+		"no verify function": {
+			expected: `length = 20
+
+rule "charset" {
+  charset = "abcdefghijklmnopqrstuvwxyz"
+  min-chars = 1
+}
+
+rule "charset" {
+  charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+  min-chars = 1
+}
+
+rule "charset" {
+  charset = "0123456789"
+  min-chars = 1
+}
+`,
+		},
+		"strong": {
+			complexity: verifyFunctionComplexity["ORA12C_STRONG_VERIFY_FUNCTION"],
+			expected: `length = 20
+
+rule "charset" {
+  charset = "abcdefghijklmnopqrstuvwxyz"
+  min-chars = 2
+}
+
+rule "charset" {
+  charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+  min-chars = 2
+}
+
+rule "charset" {
+  charset = "0123456789"
+  min-chars = 2
+}
+
+rule "charset" {
+  charset = "!#$%*+-:=?_"
+  min-chars = 2
+}
+`,
+		},
+		"longer than the default": {
+			complexity: passwordComplexity{length: 24, letters: 3},
+			expected: `length = 24
+
+rule "charset" {
+  charset = "abcdefghijklmnopqrstuvwxyz"
+  min-chars = 3
+}
+
+rule "charset" {
+  charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+  min-chars = 1
+}
+
+rule "charset" {
+  charset = "0123456789"
+  min-chars = 1
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := test.complexity.passwordPolicy()
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializePasswordProfile(t *testing.T) {
+	type testCase struct {
+		profile  string
+		limits   map[string]string
+		verify   bool
+		expected map[string]interface{}
+	}
+
+	stig := verifyFunctionComplexity["ORA12C_STIG_VERIFY_FUNCTION"]
+	tests := map[string]testCase{
+		"known verify function": {
+			profile: "app_users",
+			limits: map[string]string{
+				"APP_USERS": "ORA12C_STIG_VERIFY_FUNCTION",
+			},
+			verify: true,
+			expected: map[string]interface{}{
+				"profile":         "APP_USERS",
+				"verify_function": "ORA12C_STIG_VERIFY_FUNCTION",
+				"min_length":      15,
+				"min_letters":     0,
+				"min_uppercase":   1,
+				"min_lowercase":   1,
+				"min_digits":      1,
+				"min_special":     1,
+				"password_policy": stig.passwordPolicy(),
+			},
+		},
+		"inherited from DEFAULT": {
+			profile: "app_users",
+			limits: map[string]string{
+				"APP_USERS": "DEFAULT",
+				"DEFAULT":   "CUSTOM_VERIFY",
+			},
+			verify: true,
+			expected: map[string]interface{}{
+				"profile":         "APP_USERS",
+				"verify_function": "CUSTOM_VERIFY",
+			},
+		},
+		"connection not verified": {
+			profile: "DEFAULT",
+			limits: map[string]string{
+				"DEFAULT": "ORA12C_VERIFY_FUNCTION",
+			},
+		},
+		"not set": {
+			verify: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, nil)
+			fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+				if strings.Contains(query, "dba_profiles") {
+					limit, ok := test.limits[args[0].(string)]
+					if !ok {
+						return &fakeRows{}, nil
+					}
+					return &fakeRows{
+						columns: []string{"LIMIT"},
+						values:  [][]driver.Value{{limit}},
+					}, nil
+				}
+				return &fakeRows{}, nil
+			}
+
+			config := map[string]interface{}{
+				"connection_url": "{{username}}/{{password}}@fakehost:1521/" + strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()),
+				"username":       "vaultadmin",
+				"password":       "r00tPassw0rd",
+			}
+			if test.profile != "" {
+				config["password_profile"] = test.profile
+			}
+			resp, err := db.Initialize(context.Background(), dbplugin.InitializeRequest{
+				Config:           config,
+				VerifyConnection: test.verify,
+			})
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			actual, ok := resp.Config[passwordProfileRulesKey]
+			if test.expected == nil {
+				if ok {
+					t.Fatalf("expected no password rules, got %v", actual)
+				}
+				return
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializePasswordProfileFailure(t *testing.T) {
+	db, _ := newFakeOracle(t, nil)
+
+	// The profile doesn't exist
+	_, err := db.Initialize(context.Background(), dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":   "{{username}}/{{password}}@fakehost:1521/" + t.Name(),
+			"username":         "vaultadmin",
+			"password":         "r00tPassw0rd",
+			"password_profile": "missing",
+		},
+		VerifyConnection: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "profile MISSING does not exist") {
+		t.Fatalf("expected the missing profile to be reported, got: %v", err)
+	}
+
+	_, err = db.Initialize(context.Background(), dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":   "{{username}}/{{password}}@fakehost:1521/" + t.Name(),
+			"username":         "vaultadmin",
+			"password":         "r00tPassw0rd",
+			"password_profile": "app users",
+		},
+	})
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultPasswordRolloverProfile is the profile users rotated with `password_rollover_time` are assigned.
+	defaultPasswordRolloverProfile = "VAULT_PASSWORD_ROLLOVER"
+
+	// minPasswordRolloverTime and maxPasswordRolloverTime are the limits Oracle puts on PASSWORD_ROLLOVER_TIME.
+	minPasswordRolloverTime = time.Hour
+	maxPasswordRolloverTime = 60 * 24 * time.Hour
+
+	// oraProfileExists is the error code of CREATE PROFILE for an existing profile (ORA-02379).
+	oraProfileExists = 2379
+
+	// userProfileSQL looks up the name and profile of the user bound to :1 and :2, stored in upper case unless it was
+	// quoted. A name stored exactly as :3 is preferred.
+	userProfileSQL = `SELECT username, profile FROM dba_users WHERE username IN (:1, UPPER(:2)) ORDER BY DECODE(username, :3, 0, 1)`
+
+	// assignProfileSQL assigns the profile substituted for %s to the user bound to :1, quoted by DBMS_ASSERT as stored
+	// in DBA_USERS, so that the username can't alter the statement.
+	assignProfileSQL = `BEGIN
+  EXECUTE IMMEDIATE 'ALTER USER ' || DBMS_ASSERT.ENQUOTE_NAME(:1, FALSE) || ' PROFILE %s';
+END;`
+)
+
+// supportsPasswordRollover reports whether the server supports gradual password rollover, which was introduced
+// in 21c and backported to 19.12.
+func supportsPasswordRollover(version serverVersion) bool {
+	return version.atLeast(21, 0) || version.major == 19 && version.atLeast(19, 12)
+}
+
+// currentPasswordRolloverTime returns the PASSWORD_ROLLOVER_TIME of the profile of the connected user. During that
+// time after a password change, the user can still log in with the previous password. Zero means that the
+// previous password stops working right away.
+func currentPasswordRolloverTime(ctx context.Context, conn *sql.Conn) (time.Duration, error) {
+	var limit string
+	err := conn.QueryRowContext(ctx, `SELECT "LIMIT" FROM user_password_limits WHERE resource_name = 'PASSWORD_ROLLOVER_TIME'`).Scan(&limit)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to query password rollover time: %w", classifyError(err))
+	}
+	return parsePasswordRolloverTime(limit), nil
+}
+
+// parsePasswordRolloverTime converts a PASSWORD_ROLLOVER_TIME limit, in days, to a duration. Limits that aren't a
+// number, such as DEFAULT, disable the rollover.
+func parsePasswordRolloverTime(limit string) time.Duration {
+	days, err := strconv.ParseFloat(strings.TrimSpace(limit), 64)
+	if err != nil || days <= 0 {
+		return 0
+	}
+	return time.Duration(days * float64(24*time.Hour)).Round(time.Second)
+}
+
+// validatePasswordRolloverTime checks that the rollover time is within the limits of PASSWORD_ROLLOVER_TIME, or
+// zero to rotate passwords without a rollover period.
+func validatePasswordRolloverTime(rollover time.Duration) error {
+	if rollover != 0 && (rollover < minPasswordRolloverTime || rollover > maxPasswordRolloverTime) {
+		return fmt.Errorf("'password_rollover_time' must be between %s and %s, or 0 to disable it", minPasswordRolloverTime, maxPasswordRolloverTime)
+	}
+	return nil
+}
+
+// normalizePasswordRolloverProfile validates the name of the rollover profile and returns it in upper case. It is
+// substituted into the profile statements, so it has to be a nonquoted identifier.
+func normalizePasswordRolloverProfile(profile string) (string, error) {
+	err := validateIdentifier(profile, maxIdentifierBytes, false)
+	if err != nil {
+		return "", fmt.Errorf("invalid password_rollover_profile %q: %w", profile, err)
+	}
+	return strings.ToUpper(profile), nil
+}
+
+// passwordRolloverLimit formats a rollover time as the expression, in days, PASSWORD_ROLLOVER_TIME is set to.
+func passwordRolloverLimit(rollover time.Duration) string {
+	return fmt.Sprintf("%d/86400", int64(rollover/time.Second))
+}
+
+// assignPasswordRolloverProfile assigns the rollover profile to the user, so that its previous password keeps
+// working for `password_rollover_time` after the next password change. It reports whether the rollover applies:
+// users with a profile other than DEFAULT keep it, rather than losing its limits, and are rotated without a rollover
+// period.
+func (o *Oracle) assignPasswordRolloverProfile(ctx context.Context, conn *sql.Conn, username, container string, version serverVersion) (bool, error) {
+	if version.major != 0 && !supportsPasswordRollover(version) {
+		return false, fmt.Errorf("password_rollover_time requires Oracle 19.12 or later, the server runs %s", version)
+	}
+	profile := o.passwordRolloverProfile
+	if container == rootContainer && !strings.HasPrefix(profile, commonUserPrefix) {
+		return false, fmt.Errorf("profiles created in %s must be prefixed with %s, got password_rollover_profile %q", rootContainer, commonUserPrefix, profile)
+	}
+
+	var storedName, current string
+	err := conn.QueryRowContext(ctx, userProfileSQL, username, username, username).Scan(&storedName, &current)
+	switch {
+	case err == sql.ErrNoRows:
+		// The password change reports the missing user
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("unable to query the profile of %s: %w", username, classifyError(err))
+	case current != defaultProfile && current != profile:
+		o.logger.Warn("user has another profile than password_rollover_profile, rotating without a rollover period",
+			"username", username, "profile", current, "password_rollover_profile", profile)
+		return false, nil
+	}
+
+	err = o.setUpPasswordRolloverProfile(ctx, conn, container)
+	if err != nil {
+		return false, err
+	}
+	if current == profile {
+		return true, nil
+	}
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(assignProfileSQL, profile), storedName)
+	if err != nil {
+		return false, fmt.Errorf("unable to assign password rollover profile %s: %w", profile, classifyError(err))
+	}
+	return true, nil
+}
+
+// setUpPasswordRolloverProfile creates the rollover profile, or updates its PASSWORD_ROLLOVER_TIME if it exists so
+// that it follows the config. This is done once per container after Initialize, rather than on every rotation.
+func (o *Oracle) setUpPasswordRolloverProfile(ctx context.Context, conn *sql.Conn, container string) error {
+	o.rolloverProfilesLock.Lock()
+	defer o.rolloverProfilesLock.Unlock()
+	if o.rolloverProfiles[container] {
+		return nil
+	}
+
+	profile := o.passwordRolloverProfile
+	limit := " LIMIT PASSWORD_ROLLOVER_TIME " + passwordRolloverLimit(o.passwordRolloverTime)
+	_, err := conn.ExecContext(ctx, "CREATE PROFILE "+profile+limit)
+	var oraErr *OracleError
+	if errors.As(classifyError(err), &oraErr) && oraErr.Code == oraProfileExists {
+		_, err = conn.ExecContext(ctx, "ALTER PROFILE "+profile+limit)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set up password rollover profile %s: %w", profile, classifyError(err))
+	}
+
+	if o.rolloverProfiles == nil {
+		o.rolloverProfiles = map[string]bool{}
+	}
+	o.rolloverProfiles[container] = true
+	return nil
+}
+
+// logPasswordRollover logs when the previous password of a user stops working.
+func (o *Oracle) logPasswordRollover(username string, rollover time.Duration) {
+	o.logger.Info("rotated password, the previous password remains valid until the end of the rollover period",
+		"username", username, "rollover_end", time.Now().Add(rollover).UTC().Format(time.RFC3339))
+}
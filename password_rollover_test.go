@@ -0,0 +1,393 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestParsePasswordRolloverTime(t *testing.T) {
+	type testCase struct {
+		limit    string
+		expected time.Duration
+	}
+
+	tests := map[string]testCase{
+		"days": {
+			limit:    "7",
+			expected: 7 * 24 * time.Hour,
+		},
+		"one hour": {
+			limit:    ".0416666667",
+			expected: time.Hour,
+		},
+		"disabled": {
+			limit:    "0",
+			expected: 0,
+		},
+		"default": {
+			limit:    "DEFAULT",
+			expected: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := parsePasswordRolloverTime(test.limit)
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestSupportsPasswordRollover(t *testing.T) {
+	tests := map[string]bool{
+		"":      false,
+		"19.11": false,
+		"19.12": true,
+		"21.3":  true,
+		"23.4":  true,
+	}
+
+	for version, expected := range tests {
+		t.Run(version, func(t *testing.T) {
+			v := serverVersion{}
+			if version != "" {
+				var err error
+				v, err = parseServerVersion(version)
+				if err != nil {
+					t.Fatalf("no error expected, got: %s", err)
+				}
+			}
+			actual := supportsPasswordRollover(v)
+			if actual != expected {
+				t.Fatalf("Actual: %t\nExpected: %t", actual, expected)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializePasswordRollover(t *testing.T) {
+	type testCase struct {
+		rolloverTime interface{}
+		profile      interface{}
+
+		expectedRolloverTime time.Duration
+		expectedProfile      string
+		expectErr            bool
+	}
+
+	tests := map[string]testCase{
+		"default": {
+			expectedProfile: defaultPasswordRolloverProfile,
+		},
+		"rollover time": {
+			rolloverTime:         "1h",
+			expectedRolloverTime: time.Hour,
+			expectedProfile:      defaultPasswordRolloverProfile,
+		},
+		"rollover time in seconds": {
+			rolloverTime:         86400,
+			profile:              "app_rollover",
+			expectedRolloverTime: 24 * time.Hour,
+			expectedProfile:      "APP_ROLLOVER",
+		},
+		"too short": {
+			rolloverTime: "30m",
+			expectErr:    true,
+		},
+		"too long": {
+			rolloverTime: "1441h",
+			expectErr:    true,
+		},
+		"invalid profile": {
+			rolloverTime:         "1h",
+			profile:              "vault profile",
+			expectedRolloverTime: time.Hour,
+			expectErr:            true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := new()
+
+			config := map[string]interface{}{
+				"connection_url": "system/oracle@localhost:1521/xe",
+			}
+			if test.rolloverTime != nil {
+				config["password_rollover_time"] = test.rolloverTime
+			}
+			if test.profile != nil {
+				config["password_rollover_profile"] = test.profile
+			}
+			req := dbplugin.InitializeRequest{
+				Config:           config,
+				VerifyConnection: false,
+			}
+
+			_, err := db.Initialize(context.Background(), req)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if db.passwordRolloverTime != test.expectedRolloverTime {
+				t.Fatalf("Actual: %s\nExpected: %s", db.passwordRolloverTime, test.expectedRolloverTime)
+			}
+			if db.passwordRolloverProfile != test.expectedProfile {
+				t.Fatalf("Actual: %q\nExpected: %q", db.passwordRolloverProfile, test.expectedProfile)
+			}
+		})
+	}
+}
+
+func TestOracle_UpdateUserPasswordRollover(t *testing.T) {
+	type testCase struct {
+		config        map[string]interface{}
+		version       string
+		conName       string
+		profileExists bool
+
+		// username is the name the user is rotated as, APP_USER by default
+		username string
+
+		// storedName and userProfile are the name and current profile of the user in DBA_USERS, which has no row
+		// for the user if userProfile is empty
+		storedName  string
+		userProfile string
+
+		expectedStatements []string
+		expectedAssignName string
+		expectRollover     bool
+		expectWarning      bool
+		expectErr          bool
+	}
+
+	tests := map[string]testCase{
+		"disabled": {
+			version: "21.3.0.0.0",
+			expectedStatements: []string{
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+		},
+		"new profile": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:     "19.12.0.0.0",
+			userProfile: defaultProfile,
+			expectedStatements: []string{
+				`CREATE PROFILE VAULT_PASSWORD_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 7200/86400`,
+				fmt.Sprintf(assignProfileSQL, "VAULT_PASSWORD_ROLLOVER"),
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedAssignName: "APP_USER",
+			expectRollover:     true,
+		},
+		"existing profile": {
+			config: map[string]interface{}{
+				"password_rollover_time":    "24h",
+				"password_rollover_profile": "app_rollover",
+			},
+			version:       "21.3.0.0.0",
+			profileExists: true,
+			userProfile:   defaultProfile,
+			expectedStatements: []string{
+				`CREATE PROFILE APP_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 86400/86400`,
+				`ALTER PROFILE APP_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 86400/86400`,
+				fmt.Sprintf(assignProfileSQL, "APP_ROLLOVER"),
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedAssignName: "APP_USER",
+			expectRollover:     true,
+		},
+		"quoted lowercase user": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:     "21.3.0.0.0",
+			username:    "app_user",
+			storedName:  "app_user",
+			userProfile: defaultProfile,
+			expectedStatements: []string{
+				`CREATE PROFILE VAULT_PASSWORD_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 7200/86400`,
+				fmt.Sprintf(assignProfileSQL, "VAULT_PASSWORD_ROLLOVER"),
+				`ALTER USER app_user IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedAssignName: "app_user",
+			expectRollover:     true,
+		},
+		"rollover profile assigned": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:       "21.3.0.0.0",
+			profileExists: true,
+			userProfile:   "VAULT_PASSWORD_ROLLOVER",
+			expectedStatements: []string{
+				`CREATE PROFILE VAULT_PASSWORD_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 7200/86400`,
+				`ALTER PROFILE VAULT_PASSWORD_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 7200/86400`,
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectRollover: true,
+		},
+		"other profile": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:     "21.3.0.0.0",
+			userProfile: "APP_LIMITS",
+			expectedStatements: []string{
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectWarning: true,
+		},
+		"missing user": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version: "21.3.0.0.0",
+			expectedStatements: []string{
+				`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+			},
+		},
+		"unsupported release": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:     "19.11.0.0.0",
+			userProfile: defaultProfile,
+			expectErr:   true,
+		},
+		"local profile in root container": {
+			config: map[string]interface{}{
+				"password_rollover_time": "2h",
+			},
+			version:     "21.3.0.0.0",
+			conName:     rootContainer,
+			userProfile: defaultProfile,
+			expectErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, test.config)
+			logs := &bytes.Buffer{}
+			db.logger = hclog.New(&hclog.LoggerOptions{
+				Output:     logs,
+				JSONFormat: true,
+			})
+			username := test.username
+			if username == "" {
+				username = "APP_USER"
+			}
+			storedName := test.storedName
+			if storedName == "" {
+				storedName = "APP_USER"
+			}
+			server := fakeServer(test.version, "", "NO", 1, test.conName)
+			fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+				if strings.Contains(query, "dba_users") && test.userProfile != "" {
+					return &fakeRows{
+						columns: []string{"USERNAME", "PROFILE"},
+						values:  [][]driver.Value{{storedName, test.userProfile}},
+					}, nil
+				}
+				return server(query, args)
+			}
+			var assignName driver.Value
+			fdb.exec = func(query string, args []driver.Value) error {
+				if test.profileExists && strings.HasPrefix(query, "CREATE PROFILE") {
+					return errors.New("ORA-02379: profile APP_ROLLOVER already exists")
+				}
+				if strings.Contains(query, " PROFILE ") && len(args) == 1 {
+					assignName = args[0]
+				}
+				return nil
+			}
+
+			_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+				Username: username,
+				Password: &dbplugin.ChangePassword{
+					NewPassword: "n3wPassw0rd",
+				},
+			})
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			actual := fdb.statements()
+			if !reflect.DeepEqual(actual, test.expectedStatements) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expectedStatements)
+			}
+			if test.expectedAssignName != "" && assignName != test.expectedAssignName {
+				t.Fatalf("Actual: %v\nExpected: %s", assignName, test.expectedAssignName)
+			}
+			if strings.Contains(logs.String(), `"rollover_end"`) != test.expectRollover {
+				t.Fatalf("expected the end of the rollover period to be logged: %t, got %s", test.expectRollover, logs)
+			}
+			if strings.Contains(logs.String(), `"@level":"warn"`) != test.expectWarning {
+				t.Fatalf("expected a warning to be logged: %t, got %s", test.expectWarning, logs)
+			}
+		})
+	}
+}
+
+func TestOracle_UpdateUserPasswordRolloverProfileSetUpOnce(t *testing.T) {
+	db, fdb := newFakeOracle(t, map[string]interface{}{
+		"password_rollover_time": "2h",
+	})
+	server := fakeServer("21.3.0.0.0", "", "NO", 1, "")
+	fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+		if strings.Contains(query, "dba_users") {
+			return &fakeRows{
+				columns: []string{"USERNAME", "PROFILE"},
+				values:  [][]driver.Value{{args[0], defaultProfile}},
+			}, nil
+		}
+		return server(query, args)
+	}
+
+	for _, username := range []string{"APP_USER", "OTHER_USER"} {
+		_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+			Username: username,
+			Password: &dbplugin.ChangePassword{
+				NewPassword: "n3wPassw0rd",
+			},
+		})
+		if err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+	}
+
+	expected := []string{
+		`CREATE PROFILE VAULT_PASSWORD_ROLLOVER LIMIT PASSWORD_ROLLOVER_TIME 7200/86400`,
+		fmt.Sprintf(assignProfileSQL, "VAULT_PASSWORD_ROLLOVER"),
+		`ALTER USER APP_USER IDENTIFIED BY "n3wPassw0rd"`,
+		fmt.Sprintf(assignProfileSQL, "VAULT_PASSWORD_ROLLOVER"),
+		`ALTER USER OTHER_USER IDENTIFIED BY "n3wPassw0rd"`,
+	}
+	actual := fdb.statements()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %#v\nExpected: %#v", actual, expected)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+)
+
+const (
+	// passwordWalletFile is the auto-login wallet holding the root credential in the Secure External Password
+	// Store.
+	passwordWalletFile = "cwallet.sso"
+
+	// redactedPasswordWallet replaces the password_wallet field in logs.
+	redactedPasswordWallet = "[password_wallet]"
+)
+
+var (
+	// passwordWalletMagic starts every Oracle SSO wallet, and is followed by its version.
+	passwordWalletMagic = []byte{0xa1, 0xf8, 0x4e}
+
+	// passwordWalletLocalVersion is the version of local auto-login wallets, which only open on the host and for
+	// the OS user they were created by.
+	passwordWalletLocalVersion = byte(0x38)
+)
+
+// passwordWallet is a temporary directory holding the auto-login wallet of the password_wallet field.
+type passwordWallet struct {
+	dir string
+
+	// encoded is the field as configured, so that it can be redacted.
+	encoded string
+}
+
+// parsePasswordWallet decodes the password_wallet field of the config. The second return value reports whether it
+// is set.
+func parsePasswordWallet(config map[string]interface{}) ([]byte, string, bool, error) {
+	encoded, err := strutil.GetString(config, "password_wallet")
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to retrieve password_wallet: %w", err)
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, "", false, nil
+	}
+	if !passwordWalletSupported {
+		return nil, "", false, fmt.Errorf("password_wallet is not supported by the %s driver", driverName)
+	}
+
+	wallet, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("password_wallet must be base64 encoded: %w", err)
+	}
+	if len(wallet) <= len(passwordWalletMagic) || !bytes.HasPrefix(wallet, passwordWalletMagic) {
+		return nil, "", false, fmt.Errorf("password_wallet is not an auto-login wallet (cwallet.sso)")
+	}
+	if wallet[len(passwordWalletMagic)] == passwordWalletLocalVersion {
+		return nil, "", false, fmt.Errorf("password_wallet is a local auto-login wallet, which can only be opened on the host it was created on")
+	}
+	return wallet, encoded, true, nil
+}
+
+// writePasswordWallet writes the wallet into a new temporary directory that only the plugin can read.
+func writePasswordWallet(wallet []byte, encoded string) (*passwordWallet, error) {
+	// The directory is created with 0700 permissions
+	dir, err := os.MkdirTemp("", "vault-plugin-database-oracle-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, passwordWalletFile), wallet, 0o600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write wallet: %w", err)
+	}
+
+	return &passwordWallet{
+		dir:     dir,
+		encoded: encoded,
+	}, nil
+}
+
+// remove deletes the wallet directory.
+func (w *passwordWallet) remove() error {
+	if w == nil {
+		return nil
+	}
+	return os.RemoveAll(w.dir)
+}
+
+// validatePasswordWalletConfig checks that the root credential isn't also configured in clear text, and that the
+// username is set, as rotating it has to be refused.
+func validatePasswordWalletConfig(config map[string]interface{}, connURL string, useTLS bool) error {
+	username, err := strutil.GetString(config, "username")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve username: %w", err)
+	}
+	password, err := strutil.GetString(config, "password")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve password: %w", err)
+	}
+
+	switch {
+	case username == "":
+		return fmt.Errorf("username is required with password_wallet")
+	case password != "":
+		return fmt.Errorf("password cannot be set with password_wallet")
+	case redactConnectionURL(connURL) != connURL:
+		return fmt.Errorf("connection_url cannot contain a password with password_wallet")
+	case useTLS:
+		return fmt.Errorf("password_wallet cannot be combined with the tls_* fields")
+	}
+	return nil
+}
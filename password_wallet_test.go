@@ -0,0 +1,212 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build goora
+
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// testPasswordWallet is the header of an auto-login wallet, which is all the plugin checks.
+var testPasswordWallet = append([]byte{0xa1, 0xf8, 0x4e, 0x36}, []byte("wallet")...)
+
+func TestParsePasswordWallet(t *testing.T) {
+	type testCase struct {
+		config      map[string]interface{}
+		expectedSet bool
+		expectErr   bool
+	}
+
+	tests := map[string]testCase{
+		"not set": {
+			config: map[string]interface{}{},
+		},
+		"auto-login wallet": {
+			config: map[string]interface{}{
+				"password_wallet": base64.StdEncoding.EncodeToString(testPasswordWallet),
+			},
+			expectedSet: true,
+		},
+		"invalid base64": {
+			config: map[string]interface{}{
+				"password_wallet": "not base64!",
+			},
+			expectErr: true,
+		},
+		"not a wallet": {
+			config: map[string]interface{}{
+				"password_wallet": base64.StdEncoding.EncodeToString([]byte("-----BEGIN CERTIFICATE-----")),
+			},
+			expectErr: true,
+		},
+		"local auto-login wallet": {
+			config: map[string]interface{}{
+				"password_wallet": base64.StdEncoding.EncodeToString([]byte{0xa1, 0xf8, 0x4e, 0x38, 0x00}),
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wallet, _, set, err := parsePasswordWallet(test.config)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if set != test.expectedSet {
+				t.Fatalf("Actual: %t\nExpected: %t", set, test.expectedSet)
+			}
+			if set && !bytes.Equal(wallet, testPasswordWallet) {
+				t.Fatalf("Actual: %x\nExpected: %x", wallet, testPasswordWallet)
+			}
+		})
+	}
+}
+
+func TestOracle_InitializePasswordWallet(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	encoded := base64.StdEncoding.EncodeToString(testPasswordWallet)
+	db := new()
+	req := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":  "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+			"username":        "vaultadmin",
+			"password_wallet": encoded,
+		},
+		VerifyConnection: false,
+	}
+	_, err := db.Initialize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	if db.passwordWallet == nil || !strings.HasPrefix(db.passwordWallet.dir, tmpDir) {
+		t.Fatalf("expected a password wallet in %s, got %#v", tmpDir, db.passwordWallet)
+	}
+	wallet, err := os.ReadFile(filepath.Join(db.passwordWallet.dir, passwordWalletFile))
+	if err != nil {
+		t.Fatalf("expected wallet to be written: %s", err)
+	}
+	if !bytes.Equal(wallet, testPasswordWallet) {
+		t.Fatalf("Actual: %x\nExpected: %x", wallet, testPasswordWallet)
+	}
+	if strings.Contains(db.ConnectionURL, "{{password}}") || !strings.Contains(db.ConnectionURL, "db.example.com:1521/orclpdb") {
+		t.Fatalf("expected a connection URL without a password, got %s", db.ConnectionURL)
+	}
+	if db.secretValues()[encoded] != redactedPasswordWallet {
+		t.Fatalf("expected password_wallet to be redacted, got %v", db.secretValues())
+	}
+
+	// The root credential can't be rotated, as the new password couldn't be written into the wallet
+	_, err = db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "VAULTADMIN",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "n3wPassw0rd",
+		},
+	})
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temporary directory: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected wallet to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestOracle_InitializePasswordWalletFailure(t *testing.T) {
+	pki := newTestPKI(t)
+	encoded := base64.StdEncoding.EncodeToString(testPasswordWallet)
+
+	type testCase struct {
+		config map[string]interface{}
+	}
+
+	tests := map[string]testCase{
+		"password": {
+			config: map[string]interface{}{
+				"connection_url":  "{{username}}/{{password}}@db.example.com:1521/orclpdb",
+				"username":        "vaultadmin",
+				"password":        "r00tPassw0rd",
+				"password_wallet": encoded,
+			},
+		},
+		"password in connection_url": {
+			config: map[string]interface{}{
+				"connection_url":  "vaultadmin/r00tPassw0rd@db.example.com:1521/orclpdb",
+				"username":        "vaultadmin",
+				"password_wallet": encoded,
+			},
+		},
+		"missing username": {
+			config: map[string]interface{}{
+				"connection_url":  "/@db.example.com:1521/orclpdb",
+				"password_wallet": encoded,
+			},
+		},
+		"tls": {
+			config: map[string]interface{}{
+				"connection_url":  "{{username}}/{{password}}@db.example.com:2484/orclpdb",
+				"username":        "vaultadmin",
+				"password_wallet": encoded,
+				"tls_ca":          pki.caPEM,
+			},
+		},
+		"invalid connection_url": {
+			config: map[string]interface{}{
+				"connection_url":  "{{username}}/{{password}}@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521)))",
+				"username":        "vaultadmin",
+				"password_wallet": encoded,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("TMPDIR", tmpDir)
+
+			db := new()
+			defer db.Close()
+
+			req := dbplugin.InitializeRequest{
+				Config:           test.config,
+				VerifyConnection: false,
+			}
+			_, err := db.Initialize(context.Background(), req)
+			if err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatalf("failed to read temporary directory: %s", err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("expected wallet to be removed, got %d entries", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+const (
+	// grantProxySQL lets the dynamic user connect as the proxy target, e.g. as `V_TOKEN_...[APP_SCHEMA]`.
+	grantProxySQL = `ALTER USER {{proxy_target}} GRANT CONNECT THROUGH {{username}}`
+
+	// proxyGrantsSQL lists the schemas the user bound to :1 can connect through to, with the names as stored.
+	proxyGrantsSQL = `SELECT proxy, client FROM proxy_users WHERE proxy IN (:1, UPPER(:2))`
+
+	// revokeProxySQL revokes the grant of the proxy target bound to :1 to the user bound to :2. DDL can't take
+	// bind variables, so the names are quoted by DBMS_ASSERT, keeping the case they are stored with.
+	revokeProxySQL = `BEGIN
+  EXECUTE IMMEDIATE 'ALTER USER ' || DBMS_ASSERT.ENQUOTE_NAME(:1, FALSE) || ' REVOKE CONNECT THROUGH ' || DBMS_ASSERT.ENQUOTE_NAME(:2, FALSE);
+END;`
+)
+
+// proxyGrant is a grant letting the proxy user connect through to the client schema.
+type proxyGrant struct {
+	proxy  string
+	client string
+}
+
+// defaultProxyCreationStatements create a user that can't do anything but connect through to the proxy target.
+// They are used when a role with a proxy target has no creation statements of its own.
+var defaultProxyCreationStatements = []string{
+	`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+	`GRANT CREATE SESSION TO {{username}}`,
+}
+
+// normalizeProxyTarget validates the schema set by `DEFINE proxy_target` and returns it in upper case. It is
+// substituted into the proxy grant, so it has to be a nonquoted identifier.
+func normalizeProxyTarget(target string) (string, error) {
+	err := validateIdentifier(target, maxIdentifierBytes, false)
+	if err != nil {
+		return "", fmt.Errorf("invalid proxy_target %q: %w", target, err)
+	}
+	return strings.ToUpper(target), nil
+}
+
+// parseProxyRoles parses the comma separated roles set by `DEFINE proxy_roles`, which the proxy session is
+// limited to.
+func parseProxyRoles(roles string) ([]string, error) {
+	var parsed []string
+	for _, role := range strings.Split(roles, ",") {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+		err := validateIdentifier(role, maxIdentifierBytes, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy role %q: %w", role, err)
+		}
+		parsed = append(parsed, strings.ToUpper(role))
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("proxy_roles must not be empty")
+	}
+	return parsed, nil
+}
+
+// creationStatements returns the statements that create a user with the given type of credential: those of the
+// role, or the defaults for client certificates or proxy users if it has none, followed by the proxy grant.
+func (rs roleStatements) creationStatements(credentialType dbplugin.CredentialType) []string {
+	statements := rs.statements
+	if len(statements) == 0 {
+		switch {
+		case credentialType == dbplugin.CredentialTypeClientCertificate:
+			statements = defaultClientCertificateCreationStatements
+		case rs.proxyTarget != "":
+			statements = defaultProxyCreationStatements
+		}
+	}
+	if rs.proxyTarget == "" {
+		return statements
+	}
+
+	grant := grantProxySQL
+	if len(rs.proxyRoles) > 0 {
+		grant += ` WITH ROLE {{proxy_roles}}`
+	}
+	return append(statements[:len(statements):len(statements)], grant)
+}
+
+// proxyGrants returns the proxy grants of the user, whichever role created them. Reading PROXY_USERS requires
+// SELECT on it, or the SELECT_CATALOG_ROLE.
+func proxyGrants(ctx context.Context, tx *sql.Tx, username string) ([]proxyGrant, error) {
+	rows, err := tx.QueryContext(ctx, proxyGrantsSQL, username, username)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query proxy grants: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var grants []proxyGrant
+	for rows.Next() {
+		var grant proxyGrant
+		if err := rows.Scan(&grant.proxy, &grant.client); err != nil {
+			return nil, fmt.Errorf("unable to query proxy grants: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to query proxy grants: %w", classifyError(err))
+	}
+	return grants, nil
+}
+
+// revokeProxyGrants revokes every proxy grant of the user, so that the targets can't be connected to while the user
+// is dropped. Only roles that define `proxy_target` grant proxy connections, so the grants are only looked up for
+// them; DROP USER removes those of other users. The grants are looked up rather than taken from `DEFINE
+// proxy_target`, as the creation statements may have granted other targets. If they can't be looked up, only the
+// proxy target of the statements is revoked.
+func (o *Oracle) revokeProxyGrants(ctx context.Context, tx *sql.Tx, username, proxyTarget string) error {
+	if proxyTarget == "" {
+		o.logger.Debug("role has no proxy_target, relying on DROP USER to revoke proxy grants", "username", username)
+		return nil
+	}
+
+	grants, err := proxyGrants(ctx, tx, username)
+	if err != nil {
+		o.logger.Warn("unable to look up the proxy grants of the user, revoking proxy_target only",
+			"username", username, "proxy_target", proxyTarget, "error", err)
+		grants = []proxyGrant{{proxy: strings.ToUpper(username), client: proxyTarget}}
+	}
+
+	for _, grant := range grants {
+		_, err := tx.ExecContext(ctx, revokeProxySQL, grant.client, grant.proxy)
+		if err != nil {
+			return fmt.Errorf("failed to revoke proxy grant to %s: %w", grant.client, classifyError(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestParseProxyRoles(t *testing.T) {
+	type testCase struct {
+		roles       string
+		expected    []string
+		expectedErr bool
+	}
+
+	tests := map[string]testCase{
+		"single role": {
+			roles:    "app_rw",
+			expected: []string{"APP_RW"},
+		},
+		"multiple roles": {
+			roles:    "app_rw, APP_RO ,reporting",
+			expected: []string{"APP_RW", "APP_RO", "REPORTING"},
+		},
+		"empty": {
+			roles:       " , ",
+			expectedErr: true,
+		},
+		"quoted role": {
+			roles:       `"app_rw"`,
+			expectedErr: true,
+		},
+		"injected statement": {
+			roles:       "app_rw; GRANT DBA TO PUBLIC",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			roles, err := parseProxyRoles(test.roles)
+			if test.expectedErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectedErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if !reflect.DeepEqual(roles, test.expected) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", roles, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_PrepareProxyStatements(t *testing.T) {
+	type testCase struct {
+		commands           []string
+		expectedTarget     string
+		expectedRoles      []string
+		expectedStatements []string
+		expectedErr        bool
+	}
+
+	tests := map[string]testCase{
+		"no proxy": {
+			commands:           []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+			expectedStatements: []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+		},
+		"default creation statements": {
+			commands:       []string{"DEFINE proxy_target = app_schema"},
+			expectedTarget: "APP_SCHEMA",
+			expectedStatements: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+				`GRANT CREATE SESSION TO {{username}}`,
+				`ALTER USER {{proxy_target}} GRANT CONNECT THROUGH {{username}}`,
+			},
+		},
+		"with roles": {
+			commands: []string{
+				"DEFINE proxy_target = app_schema",
+				"DEFINE proxy_roles = 'app_rw, app_ro'",
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}" PROFILE proxy_users`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+			expectedTarget: "APP_SCHEMA",
+			expectedRoles:  []string{"APP_RW", "APP_RO"},
+			expectedStatements: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}" PROFILE proxy_users`,
+				`GRANT CREATE SESSION TO {{username}}`,
+				`ALTER USER {{proxy_target}} GRANT CONNECT THROUGH {{username}} WITH ROLE {{proxy_roles}}`,
+			},
+		},
+		"roles without target": {
+			commands:    []string{"DEFINE proxy_roles = app_rw"},
+			expectedErr: true,
+		},
+		"invalid target": {
+			commands:    []string{`DEFINE proxy_target = 'app_schema GRANT CONNECT THROUGH x;'`},
+			expectedErr: true,
+		},
+	}
+
+	db := new()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rs, err := db.prepareStatements(test.commands)
+			if test.expectedErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectedErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if test.expectedErr {
+				return
+			}
+
+			if rs.proxyTarget != test.expectedTarget {
+				t.Fatalf("Actual: %q\nExpected: %q", rs.proxyTarget, test.expectedTarget)
+			}
+			if !reflect.DeepEqual(rs.proxyRoles, test.expectedRoles) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", rs.proxyRoles, test.expectedRoles)
+			}
+			statements := rs.creationStatements(dbplugin.CredentialTypePassword)
+			if !reflect.DeepEqual(statements, test.expectedStatements) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", statements, test.expectedStatements)
+			}
+		})
+	}
+}
+
+func TestOracle_NewProxyUser(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+
+	req := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "proxy",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{
+				"DEFINE proxy_target = app_schema",
+				"DEFINE proxy_roles = 'app_rw, app_ro'",
+			},
+		},
+		Password:   "p4ssw0rd",
+		Expiration: time.Now().Add(time.Hour),
+	}
+	resp, err := db.NewUser(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	expected := []string{
+		`CREATE USER ` + resp.Username + ` IDENTIFIED BY "p4ssw0rd"`,
+		`GRANT CREATE SESSION TO ` + resp.Username,
+		`ALTER USER APP_SCHEMA GRANT CONNECT THROUGH ` + resp.Username + ` WITH ROLE APP_RW, APP_RO`,
+	}
+	actual := fdb.statements()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %#v\nExpected: %#v", actual, expected)
+	}
+}
+
+func TestOracle_DeleteProxyUser(t *testing.T) {
+	type testCase struct {
+		statements []string
+
+		// grants are the rows of PROXY_USERS, nil if it can't be queried
+		grants [][]driver.Value
+
+		expectedRevoked [][]driver.Value
+		expectNoLookup  bool
+	}
+
+	tests := map[string]testCase{
+		"grants looked up": {
+			statements: []string{"DEFINE proxy_target = app_schema", "DROP USER {{username}}"},
+			grants: [][]driver.Value{
+				{"V_TOKEN_PROXY_1234", "APP_SCHEMA"},
+				{"V_TOKEN_PROXY_1234", "Report_Schema"},
+			},
+			expectedRevoked: [][]driver.Value{
+				{"APP_SCHEMA", "V_TOKEN_PROXY_1234"},
+				{"Report_Schema", "V_TOKEN_PROXY_1234"},
+			},
+		},
+		"grants differ from proxy_target": {
+			statements: []string{"DEFINE proxy_target = other_schema", "DROP USER {{username}}"},
+			grants: [][]driver.Value{
+				{"V_TOKEN_PROXY_1234", "APP_SCHEMA"},
+			},
+			expectedRevoked: [][]driver.Value{
+				{"APP_SCHEMA", "V_TOKEN_PROXY_1234"},
+			},
+		},
+		"without proxy_target": {
+			statements: []string{"DROP USER {{username}}"},
+			grants: [][]driver.Value{
+				{"V_TOKEN_PROXY_1234", "APP_SCHEMA"},
+			},
+			expectNoLookup: true,
+		},
+		"no grants": {
+			statements: []string{"DEFINE proxy_target = app_schema", "DROP USER {{username}}"},
+			grants:     [][]driver.Value{},
+		},
+		"lookup fails with proxy_target": {
+			statements: []string{"DEFINE proxy_target = app_schema", "DROP USER {{username}}"},
+			expectedRevoked: [][]driver.Value{
+				{"APP_SCHEMA", "V_TOKEN_PROXY_1234"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"disconnect_sessions": false,
+			})
+			lookedUp := false
+			fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+				switch {
+				case strings.Contains(query, "all_users"):
+					return &fakeRows{
+						columns: []string{"COUNT(*)"},
+						values:  [][]driver.Value{{int64(1)}},
+					}, nil
+				case strings.Contains(query, "proxy_users"):
+					lookedUp = true
+					if test.grants == nil {
+						return nil, errors.New("ORA-00942: table or view does not exist")
+					}
+					return &fakeRows{
+						columns: []string{"PROXY", "CLIENT"},
+						values:  test.grants,
+					}, nil
+				}
+				return &fakeRows{}, nil
+			}
+			var revoked [][]driver.Value
+			fdb.exec = func(query string, args []driver.Value) error {
+				if query == revokeProxySQL {
+					revoked = append(revoked, args)
+				}
+				return nil
+			}
+
+			req := dbplugin.DeleteUserRequest{
+				Username: "V_TOKEN_PROXY_1234",
+				Statements: dbplugin.Statements{
+					Commands: test.statements,
+				},
+			}
+			_, err := db.DeleteUser(context.Background(), req)
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if !reflect.DeepEqual(revoked, test.expectedRevoked) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", revoked, test.expectedRevoked)
+			}
+			if lookedUp == test.expectNoLookup {
+				t.Fatalf("expected PROXY_USERS to be queried: %t", !test.expectNoLookup)
+			}
+
+			// The grants are revoked before the user is dropped
+			statements := fdb.statements()
+			if statements[len(statements)-1] != "DROP USER V_TOKEN_PROXY_1234" {
+				t.Fatalf("expected the user to be dropped last, got %#v", statements)
+			}
+		})
+	}
+}
+
+func TestOracle_DeleteProxyUserAlreadyRevoked(t *testing.T) {
+	db, fdb := newFakeOracle(t, map[string]interface{}{
+		"disconnect_sessions": false,
+	})
+	fdb.exec = func(query string, _ []driver.Value) error {
+		if strings.Contains(query, "REVOKE CONNECT THROUGH") {
+			return fmt.Errorf("ORA-01918: user 'V_TOKEN_PROXY_1234' does not exist")
+		}
+		return nil
+	}
+	fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+		if strings.Contains(query, "all_users") {
+			return &fakeRows{
+				columns: []string{"COUNT(*)"},
+				values:  [][]driver.Value{{int64(0)}},
+			}, nil
+		}
+		return &fakeRows{}, nil
+	}
+
+	req := dbplugin.DeleteUserRequest{
+		Username: "V_TOKEN_PROXY_1234",
+		Statements: dbplugin.Statements{
+			Commands: []string{
+				"DEFINE proxy_target = app_schema",
+				"DROP USER {{username}}",
+			},
+		},
+	}
+	_, err := db.DeleteUser(context.Background(), req)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if actual := fdb.statements(); len(actual) != 0 {
+		t.Fatalf("expected no statements for a dropped user, got %#v", actual)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"net/url"
+	"strings"
+
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// redactedPassword replaces passwords in error messages.
+const redactedPassword = "[password]"
+
+// redactedError hides secrets in the message of an error, while keeping the original error available to
+// errors.Is and errors.As. Only the message crosses the plugin boundary.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError replaces every occurrence of the given secrets in the message of err. Oracle echoes the
+// offending statement in some errors, and the driver may echo the connection URL, so the URL-escaped form
+// of each secret is replaced as well. Empty secrets are ignored.
+func redactError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	for _, secret := range secretForms(secrets...) {
+		msg = strings.ReplaceAll(msg, secret, redactedPassword)
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{
+		msg: msg,
+		err: err,
+	}
+}
+
+// secretForms returns the non-empty secrets along with their URL-escaped forms.
+func secretForms(secrets ...string) []string {
+	forms := []string{}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		forms = append(forms, secret)
+		if escaped := url.PathEscape(secret); escaped != secret {
+			forms = append(forms, escaped)
+		}
+	}
+	return forms
+}
+
+// newUserSecrets returns the secrets of a NewUser request that must not appear in its errors.
+func newUserSecrets(req dbplugin.NewUserRequest) []string {
+	return []string{req.Password}
+}
+
+// updateUserSecrets returns the secrets of an UpdateUser request that must not appear in its errors.
+func updateUserSecrets(req dbplugin.UpdateUserRequest) []string {
+	secrets := []string{req.SelfManagedPassword}
+	if req.Password != nil {
+		secrets = append(secrets, req.Password.NewPassword)
+	}
+	return secrets
+}
+
+// redactConnectionURL replaces the password of a connection URL in either driver's format, unless it is the
+// {{password}} placeholder.
+func redactConnectionURL(connURL string) string {
+	prefix, rest, separators := "", connURL, ":/"
+	if isGoOraURL(connURL) {
+		prefix, rest, separators = connURL[:len(goOraURLPrefix)], connURL[len(goOraURLPrefix):], ":"
+	}
+
+	authority, connect := splitRight(rest, "@")
+	i := strings.IndexAny(authority, separators)
+	if i < 0 || authority[i+1:] == "" || authority[i+1:] == "{{password}}" {
+		return connURL
+	}
+	return prefix + authority[:i+1] + redactedPassword + "@" + connect
+}
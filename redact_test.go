@@ -0,0 +1,191 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestRedactError(t *testing.T) {
+	type testCase struct {
+		err      error
+		secrets  []string
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"secret": {
+			err:      errors.New(`ORA-00922: missing or invalid option: CREATE USER foo IDENTIFIED BY "s3cr3t"`),
+			secrets:  []string{"s3cr3t"},
+			expected: `ORA-00922: missing or invalid option: CREATE USER foo IDENTIFIED BY "[password]"`,
+		},
+		"url escaped secret": {
+			err:      errors.New(`failed to connect to foo/p%2Fss@localhost:1521/xe`),
+			secrets:  []string{"p/ss"},
+			expected: `failed to connect to foo/[password]@localhost:1521/xe`,
+		},
+		"empty secret": {
+			err:      errors.New(`ORA-01017: invalid username/password`),
+			secrets:  []string{""},
+			expected: `ORA-01017: invalid username/password`,
+		},
+		"multiple secrets": {
+			err:      errors.New(`old: 0ldPass new: n3wPass`),
+			secrets:  []string{"n3wPass", "0ldPass"},
+			expected: `old: [password] new: [password]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := redactError(test.err, test.secrets...)
+			if actual.Error() != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+			if !errors.Is(actual, test.err) {
+				t.Fatalf("redacted error does not wrap the original error")
+			}
+		})
+	}
+
+	if redactError(nil, "s3cr3t") != nil {
+		t.Fatalf("expected nil error to stay nil")
+	}
+}
+
+// echoStatementError emulates Oracle errors that echo the offending statement.
+func echoStatementError(query string, args []driver.Value) error {
+	return fmt.Errorf("ORA-00922: missing or invalid option\n%s", query)
+}
+
+func TestOracle_ErrorsDoNotContainSecrets(t *testing.T) {
+	const (
+		userPassword        = "Us3rPassw0rd"
+		newPassword         = "N3wPassw0rd"
+		selfManagedPassword = "S3lfManag3d"
+		rootPassword        = "r00tPassw0rd"
+	)
+
+	type testCase struct {
+		call    func(dbplugin.Database) error
+		secrets []string
+	}
+
+	tests := map[string]testCase{
+		"NewUser": {
+			call: func(db dbplugin.Database) error {
+				_, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+					UsernameConfig: dbplugin.UsernameMetadata{
+						DisplayName: "test",
+						RoleName:    "test",
+					},
+					Statements: dbplugin.Statements{
+						Commands: []string{`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`},
+					},
+					Password:   userPassword,
+					Expiration: time.Now().Add(time.Hour),
+				})
+				return err
+			},
+			secrets: []string{userPassword, rootPassword},
+		},
+		"NewUser with PL/SQL block": {
+			call: func(db dbplugin.Database) error {
+				_, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+					UsernameConfig: dbplugin.UsernameMetadata{
+						DisplayName: "test",
+						RoleName:    "test",
+					},
+					Statements: dbplugin.Statements{
+						Commands: []string{`BEGIN EXECUTE IMMEDIATE 'CREATE USER {{username}} IDENTIFIED BY "{{password}}"'; END;`},
+					},
+					Password:   userPassword,
+					Expiration: time.Now().Add(time.Hour),
+				})
+				return err
+			},
+			secrets: []string{userPassword, rootPassword},
+		},
+		"UpdateUser password": {
+			call: func(db dbplugin.Database) error {
+				_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+					Username: "V_TEST",
+					Password: &dbplugin.ChangePassword{
+						NewPassword: newPassword,
+					},
+				})
+				return err
+			},
+			secrets: []string{newPassword, rootPassword},
+		},
+		"UpdateUser custom rotation statements": {
+			call: func(db dbplugin.Database) error {
+				_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+					Username: "V_TEST",
+					Password: &dbplugin.ChangePassword{
+						NewPassword: newPassword,
+						Statements: dbplugin.Statements{
+							Commands: []string{`ALTER USER {{username}} IDENTIFIED BY "{{password}}" ACCOUNT UNLOCK`},
+						},
+					},
+				})
+				return err
+			},
+			secrets: []string{newPassword, rootPassword},
+		},
+		"UpdateUser self-managed": {
+			call: func(db dbplugin.Database) error {
+				_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+					Username: "V_TEST",
+					Password: &dbplugin.ChangePassword{
+						NewPassword: newPassword,
+					},
+					SelfManagedPassword: selfManagedPassword,
+				})
+				return err
+			},
+			secrets: []string{newPassword, selfManagedPassword, rootPassword},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, nil)
+			fdb.exec = echoStatementError
+
+			mw := dbplugin.NewDatabaseErrorSanitizerMiddleware(db, db.secretValues)
+			err := test.call(mw)
+			if err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			for _, secret := range test.secrets {
+				if strings.Contains(err.Error(), secret) {
+					t.Fatalf("error contains secret %q: %s", secret, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateUserSecrets(t *testing.T) {
+	req := dbplugin.UpdateUserRequest{
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "N3wPassw0rd",
+		},
+		SelfManagedPassword: "S3lfManag3d",
+	}
+
+	err := redactError(errors.New("N3wPassw0rd S3lfManag3d"), updateUserSecrets(req)...)
+	if err.Error() != "[password] [password]" {
+		t.Fatalf("Actual: %s\nExpected: [password] [password]", err)
+	}
+}
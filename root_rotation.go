@@ -0,0 +1,191 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/helper/dbutil"
+	"github.com/hashicorp/vault/sdk/helper/dbtxn"
+)
+
+// isRootUser reports whether username is the user the plugin connects as.
+func (o *Oracle) isRootUser(username string) bool {
+	return o.Username != "" && strings.EqualFold(username, o.Username)
+}
+
+// rotateRootCredential changes the password of the user the plugin connects as. Unlike for other users, the new
+// password is verified with a new connection before the rotation is reported as successful, and the previous
+// password is restored if that fails, so that the plugin isn't locked out of the database. The connection producer
+// is then switched to the new password.
+//
+// If the profile of the user has a PASSWORD_ROLLOVER_TIME, the previous password keeps working until the rollover
+// period ends, e.g. for Vault nodes that haven't reloaded the config yet.
+func (o *Oracle) rotateRootCredential(ctx context.Context, newPassword string, rotateStatements []string) error {
+	if len(rotateStatements) == 0 {
+		rotateStatements = []string{defaultRotateCredsSql}
+	}
+
+	if newPassword == "" {
+		return errors.New("must provide a password")
+	}
+	if !strings.Contains(o.rootConnectionURL, "{{password}}") {
+		return errors.New("connection_url must use the {{password}} template for the root credential to be rotated")
+	}
+
+	rs, err := o.prepareStatements(rotateStatements)
+	if err != nil {
+		return err
+	}
+	if len(rs.statements) == 0 { // Extra check to protect against future changes
+		return errors.New("no rotation statements found")
+	}
+
+	lock := o.userLock(o.Username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// The rotation doesn't register as a user of the pool, which only reconnect closes, as it would wait for
+	// itself
+	db, err := o.getConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get database connection: %w", err)
+	}
+	server := o.getServerInfo(ctx, db)
+	username := o.Username
+	previousPassword := o.Password
+
+	setPassword := func(conn *sql.Conn, password string) error {
+		variables := rs.variables(map[string]string{
+			"username": username,
+			"name":     username, // backwards compatibility
+			"password": password,
+		}, server)
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("unable to create database transaction: %w", err)
+		}
+		// Effectively a no-op if the transaction commits successfully
+		defer tx.Rollback()
+
+		for _, query := range rs.statements {
+			parsedQuery := dbutil.QueryHelper(query, variables)
+			err := dbtxn.ExecuteTxQuery(ctx, tx, nil, parsedQuery)
+			if err != nil {
+				return fmt.Errorf("unable to execute query [%s]: %w", query, classifyError(err))
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return fmt.Errorf("unable to commit statements: %w", err)
+		}
+		return nil
+	}
+
+	// The whole rotation runs on one session, which stays authenticated after the password change and can
+	// therefore restore the previous password
+	var rollover time.Duration
+	err = withContainer(ctx, db, rs.container, func(conn *sql.Conn) error {
+		if supportsPasswordRollover(server.version) {
+			var err error
+			rollover, err = currentPasswordRolloverTime(ctx, conn)
+			if err != nil {
+				o.logger.Warn("unable to determine the password rollover time of the root user", "error", err)
+			}
+		}
+
+		err := setPassword(conn, newPassword)
+		if err != nil {
+			return err
+		}
+
+		verifyErr := o.verifyRootPassword(ctx, newPassword)
+		if verifyErr == nil {
+			return nil
+		}
+		err = setPassword(conn, previousPassword)
+		switch {
+		case err != nil && rollover > 0:
+			return fmt.Errorf("%w; unable to restore the previous password, which remains valid for %s: %w", verifyErr, rollover, err)
+		case err != nil:
+			return fmt.Errorf("%w; unable to restore the previous password: %w", verifyErr, err)
+		}
+		return fmt.Errorf("%w; the previous password was restored", verifyErr)
+	})
+	if err != nil {
+		return err
+	}
+
+	if rollover > 0 {
+		o.logPasswordRollover(username, rollover)
+	}
+
+	o.reconnect(ctx, newPassword)
+	return nil
+}
+
+// verifyRootPassword opens a new connection with the given root password, independent of the pool of the
+// connection producer.
+func (o *Oracle) verifyRootPassword(ctx context.Context, password string) error {
+	o.Lock()
+	driverType := o.SQLConnectionProducer.Type
+	connURL := o.rootConnectionURLWith(password)
+	o.Unlock()
+
+	db, err := sql.Open(driverType, connURL)
+	if err != nil {
+		return fmt.Errorf("unable to verify the new password: %w", err)
+	}
+	defer db.Close()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to connect with the new password: %w", classifyError(err))
+	}
+	return nil
+}
+
+// reconnect switches the connection producer to the new root password. The pool authenticated with the previous
+// password is closed once the operations using it are done, while the lock of the producer is held, so that every
+// connection opened afterwards uses the new one. Operations starting meanwhile wait for the new pool.
+func (o *Oracle) reconnect(ctx context.Context, password string) {
+	o.poolUsers.Lock()
+	defer o.poolUsers.Unlock()
+
+	o.Lock()
+	defer o.Unlock()
+
+	previous, err := o.Connection(ctx)
+	o.Password = password
+	o.ConnectionURL = o.rootConnectionURLWith(password)
+	if o.RawConfig != nil {
+		o.RawConfig["password"] = password
+	}
+	// The connection producer opens a new pool once it finds the previous one closed
+	if err == nil {
+		previous.(*sql.DB).Close()
+	}
+}
+
+// rootConnectionURLWith returns the connection URL of the root user with the given password, escaped like the
+// connection producer does.
+func (o *Oracle) rootConnectionURLWith(password string) string {
+	username := o.Username
+	if !o.DisableEscaping {
+		username = url.PathEscape(username)
+		password = url.PathEscape(password)
+	}
+	return dbutil.QueryHelper(o.rootConnectionURL, map[string]string{
+		"username": username,
+		"password": password,
+	})
+}
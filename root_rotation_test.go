@@ -0,0 +1,265 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestOracle_RotateRootCredential(t *testing.T) {
+	errLogon := errors.New("ORA-01017: invalid username/password; logon denied")
+
+	type testCase struct {
+		config map[string]interface{}
+
+		// rejectNew rejects connections with the new password, rotateErr fails the rotation statements
+		rejectNew bool
+		rotateErr bool
+
+		expectedStatements []string
+		expectedPassword   string
+		expectErr          bool
+	}
+
+	tests := map[string]testCase{
+		"verified": {
+			expectedStatements: []string{
+				`ALTER USER vaultadmin IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedPassword: "n3wPassw0rd",
+		},
+		"case insensitive username": {
+			config: map[string]interface{}{
+				"username": "VAULTADMIN",
+			},
+			expectedStatements: []string{
+				`ALTER USER VAULTADMIN IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedPassword: "n3wPassw0rd",
+		},
+		"new password rejected": {
+			rejectNew: true,
+			expectedStatements: []string{
+				`ALTER USER vaultadmin IDENTIFIED BY "n3wPassw0rd"`,
+				`ALTER USER vaultadmin IDENTIFIED BY "r00tPassw0rd"`,
+			},
+			expectedPassword: "r00tPassw0rd",
+			expectErr:        true,
+		},
+		"rotation fails": {
+			rotateErr: true,
+			expectedStatements: []string{
+				`ALTER USER vaultadmin IDENTIFIED BY "n3wPassw0rd"`,
+			},
+			expectedPassword: "r00tPassw0rd",
+			expectErr:        true,
+		},
+		"password in connection_url": {
+			config: map[string]interface{}{
+				"connection_url": "vaultadmin/r00tPassw0rd@fakehost:1521/TestOracle_RotateRootCredential_password_in_connection_url",
+			},
+			expectedPassword: "r00tPassw0rd",
+			expectErr:        true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, test.config)
+			fdb.open = func(dsn string) error {
+				if test.rejectNew && strings.Contains(dsn, "n3wPassw0rd") {
+					return errLogon
+				}
+				return nil
+			}
+			fdb.exec = func(query string, _ []driver.Value) error {
+				if test.rotateErr {
+					return errors.New("ORA-28003: password verification for the specified password failed")
+				}
+				return nil
+			}
+
+			_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+				Username: "vaultadmin",
+				Password: &dbplugin.ChangePassword{
+					NewPassword: "n3wPassw0rd",
+				},
+			})
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			actual := fdb.statements()
+			if !reflect.DeepEqual(actual, test.expectedStatements) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expectedStatements)
+			}
+			if db.Password != test.expectedPassword {
+				t.Fatalf("Actual: %s\nExpected: %s", db.Password, test.expectedPassword)
+			}
+			if !strings.Contains(db.ConnectionURL, test.expectedPassword+"@") {
+				t.Fatalf("expected the connection URL to use %s, got %s", test.expectedPassword, db.ConnectionURL)
+			}
+		})
+	}
+}
+
+func TestOracle_RotateRootCredentialReconnects(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+
+	// Open a pool with the previous password
+	_, err := db.getConnection(context.Background())
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	_, err = db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "vaultadmin",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "n3w/Passw0rd",
+		},
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	// New connections must authenticate with the new password, escaped like the connection producer does
+	var dsns []string
+	fdb.open = func(dsn string) error {
+		dsns = append(dsns, dsn)
+		if !strings.Contains(dsn, "n3w%2FPassw0rd@") {
+			return errors.New("ORA-01017: invalid username/password; logon denied")
+		}
+		return nil
+	}
+	conn, err := db.getConnection(context.Background())
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	err = conn.PingContext(context.Background())
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if len(dsns) != 1 {
+		t.Fatalf("expected a new connection, got %v", dsns)
+	}
+	if db.RawConfig["password"] != "n3w/Passw0rd" {
+		t.Fatalf("Actual: %v\nExpected: %s", db.RawConfig["password"], "n3w/Passw0rd")
+	}
+	if _, ok := db.secretValues()["n3w/Passw0rd"]; !ok {
+		t.Fatalf("expected the new password to be redacted, got %v", db.secretValues())
+	}
+}
+
+func TestOracle_RotateRootCredentialWaitsForPoolUsers(t *testing.T) {
+	db, fdb := newFakeOracle(t, map[string]interface{}{
+		"disconnect_sessions":         false,
+		"session_termination_timeout": "0s",
+	})
+	server := fakeServer("19.3.0.0.0", "19.0.0.0.0", "NO", 1, rootContainer)
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+		switch {
+		// The revocation keeps using the pool after looking up the container of the user
+		case strings.Contains(query, "cdb_users"):
+			close(blocked)
+			<-release
+		case strings.HasPrefix(query, "SELECT SYS_CONTEXT('USERENV', 'CON_NAME')"):
+			return &fakeRows{
+				columns: []string{"CON_NAME"},
+				values:  [][]driver.Value{{rootContainer}},
+			}, nil
+		}
+		return server(query, args)
+	}
+
+	deleteErr := make(chan error, 1)
+	go func() {
+		_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+			Username: "V_TEST",
+		})
+		deleteErr <- err
+	}()
+	<-blocked
+
+	rotateErr := make(chan error, 1)
+	go func() {
+		_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+			Username: "vaultadmin",
+			Password: &dbplugin.ChangePassword{
+				NewPassword: "n3wPassw0rd",
+			},
+		})
+		rotateErr <- err
+	}()
+
+	select {
+	case err := <-rotateErr:
+		t.Fatalf("expected the rotation to wait for the revocation, got: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	err := <-deleteErr
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	err = <-rotateErr
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if db.Password != "n3wPassw0rd" {
+		t.Fatalf("Actual: %s\nExpected: %s", db.Password, "n3wPassw0rd")
+	}
+}
+
+func TestOracle_RotateRootCredentialRollover(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+	logs := &bytes.Buffer{}
+	db.logger = hclog.New(&hclog.LoggerOptions{
+		Output:     logs,
+		JSONFormat: true,
+	})
+	server := fakeServer("21.3.0.0.0", "21.0.0.0.0", "NO", 0, "")
+	queried := false
+	fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+		if strings.Contains(query, "user_password_limits") {
+			queried = true
+			return &fakeRows{
+				columns: []string{"LIMIT"},
+				values:  [][]driver.Value{{"1"}},
+			}, nil
+		}
+		return server(query, args)
+	}
+
+	_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "vaultadmin",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "n3wPassw0rd",
+		},
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if !queried {
+		t.Fatalf("expected the password rollover time to be queried")
+	}
+	if !strings.Contains(logs.String(), `"rollover_end"`) {
+		t.Fatalf("expected the end of the rollover period to be logged, got %s", logs)
+	}
+}
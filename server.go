@@ -0,0 +1,144 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverDetectionRetryInterval is how long getServerInfo waits before probing the server again after a failed
+// detection.
+const serverDetectionRetryInterval = time.Minute
+
+// serverVersion is the release of the database server, e.g. 19.12. The zero value means that the version is
+// unknown.
+type serverVersion struct {
+	major int
+	minor int
+}
+
+// atLeast reports whether the server is at least the given release.
+func (v serverVersion) atLeast(major, minor int) bool {
+	return v.major > major || v.major == major && v.minor >= minor
+}
+
+func (v serverVersion) String() string {
+	if v.major == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+// serverInfo describes the database server the plugin is connected to.
+type serverInfo struct {
+	version serverVersion
+
+	// rac reports whether the database runs on Real Application Clusters. Finding out requires access to
+	// v$instance, racKnown is false if it isn't granted.
+	rac      bool
+	racKnown bool
+
+	// cdb reports whether the database is a multitenant container database, in which case container is the
+	// container that connection_url lands in.
+	cdb       bool
+	container string
+}
+
+// detectServerInfo probes the features of the database server. Only the version is required, the other
+// features are left unknown if they can't be queried.
+func detectServerInfo(ctx context.Context, db *sql.DB) (serverInfo, error) {
+	version, err := detectServerVersion(ctx, db)
+	if err != nil {
+		return serverInfo{}, err
+	}
+	server := serverInfo{
+		version: version,
+	}
+
+	var parallel string
+	err = db.QueryRowContext(ctx, `SELECT parallel FROM v$instance`).Scan(&parallel)
+	if err == nil {
+		server.rac = parallel == "YES"
+		server.racKnown = true
+	}
+
+	// Containers were introduced in 12.1
+	if version.atLeast(12, 1) {
+		var conID int
+		var conName string
+		err = db.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'CON_ID'), SYS_CONTEXT('USERENV', 'CON_NAME') FROM dual`).Scan(&conID, &conName)
+		if err == nil && conID != 0 {
+			server.cdb = true
+			server.container = strings.ToUpper(conName)
+		}
+	}
+	return server, nil
+}
+
+// detectServerVersion returns the version of the database server. product_component_version is readable by
+// every user, unlike v$instance. Its version_full column, which has the release update, was added in 18c.
+func detectServerVersion(ctx context.Context, db *sql.DB) (serverVersion, error) {
+	var version string
+	var err error
+	for _, column := range []string{"version_full", "version"} {
+		err = db.QueryRowContext(ctx, `SELECT `+column+` FROM product_component_version WHERE product LIKE 'Oracle Database%'`).Scan(&version)
+		if err == nil {
+			return parseServerVersion(version)
+		}
+	}
+	return serverVersion{}, fmt.Errorf("unable to detect server version: %w", err)
+}
+
+func parseServerVersion(version string) (serverVersion, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) < 2 {
+		return serverVersion{}, fmt.Errorf("invalid server version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("invalid server version %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("invalid server version %q", version)
+	}
+	return serverVersion{major: major, minor: minor}, nil
+}
+
+// getServerInfo returns the cached features of the server, detecting them if Initialize couldn't. If they
+// can't be detected, the zero value is returned and the defaults fall back to what works on every release.
+// A failed detection is logged and not retried for serverDetectionRetryInterval, so that a server that can't be
+// probed doesn't cost extra queries on every request.
+func (o *Oracle) getServerInfo(ctx context.Context, db *sql.DB) serverInfo {
+	o.serverLock.Lock()
+	defer o.serverLock.Unlock()
+
+	if !o.serverDetected && time.Now().After(o.serverRetryAt) {
+		server, err := detectServerInfo(ctx, db)
+		if err != nil {
+			o.logger.Warn("unable to detect the database server, falling back to defaults that work on every release",
+				"error", err, "retry_in", serverDetectionRetryInterval)
+			o.serverRetryAt = time.Now().Add(serverDetectionRetryInterval)
+			return serverInfo{}
+		}
+		o.server = server
+		o.serverDetected = true
+	}
+	return o.server
+}
+
+// resetServerInfo forgets the features of the server, e.g. because the connection was reconfigured.
+func (o *Oracle) resetServerInfo() {
+	o.serverLock.Lock()
+	defer o.serverLock.Unlock()
+
+	o.server = serverInfo{}
+	o.serverDetected = false
+	o.serverRetryAt = time.Time{}
+}
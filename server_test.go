@@ -0,0 +1,322 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	type testCase struct {
+		version   string
+		expected  serverVersion
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"11g": {
+			version:  "11.2.0.4.0",
+			expected: serverVersion{major: 11, minor: 2},
+		},
+		"12.2": {
+			version:  "12.2.0.1.0",
+			expected: serverVersion{major: 12, minor: 2},
+		},
+		"19c": {
+			version:  "19.0.0.0.0",
+			expected: serverVersion{major: 19, minor: 0},
+		},
+		"23ai": {
+			version:  " 23.4.0.24.05 ",
+			expected: serverVersion{major: 23, minor: 4},
+		},
+		"empty": {
+			version:   "",
+			expectErr: true,
+		},
+		"major only": {
+			version:   "19",
+			expectErr: true,
+		},
+		"garbage": {
+			version:   "Release 19",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseServerVersion(test.version)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("Actual: %+v\nExpected: %+v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestServerVersion_AtLeast(t *testing.T) {
+	type testCase struct {
+		version  serverVersion
+		major    int
+		minor    int
+		expected bool
+	}
+
+	tests := map[string]testCase{
+		"older major": {
+			version:  serverVersion{major: 11, minor: 2},
+			major:    12,
+			minor:    2,
+			expected: false,
+		},
+		"older minor": {
+			version:  serverVersion{major: 12, minor: 1},
+			major:    12,
+			minor:    2,
+			expected: false,
+		},
+		"same": {
+			version:  serverVersion{major: 12, minor: 2},
+			major:    12,
+			minor:    2,
+			expected: true,
+		},
+		"newer major": {
+			version:  serverVersion{major: 19, minor: 0},
+			major:    12,
+			minor:    2,
+			expected: true,
+		},
+		"unknown": {
+			version:  serverVersion{},
+			major:    12,
+			minor:    2,
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := test.version.atLeast(test.major, test.minor)
+			if actual != test.expected {
+				t.Fatalf("Actual: %t\nExpected: %t", actual, test.expected)
+			}
+		})
+	}
+}
+
+// fakeServer answers the queries of detectServerInfo. An empty value makes the corresponding query fail, like
+// it does on releases without the column or without access to the view.
+func fakeServer(versionFull, version, parallel string, conID int64, conName string) func(query string, args []driver.Value) (driver.Rows, error) {
+	return func(query string, args []driver.Value) (driver.Rows, error) {
+		errMissing := errors.New("ORA-00904: invalid identifier")
+		row := func(column string, value driver.Value) (driver.Rows, error) {
+			return &fakeRows{
+				columns: []string{column},
+				values:  [][]driver.Value{{value}},
+			}, nil
+		}
+
+		switch {
+		case strings.HasPrefix(query, "SELECT version_full FROM product_component_version"):
+			if versionFull == "" {
+				return nil, errMissing
+			}
+			return row("VERSION_FULL", versionFull)
+		case strings.HasPrefix(query, "SELECT version FROM product_component_version"):
+			if version == "" {
+				return nil, errMissing
+			}
+			return row("VERSION", version)
+		case strings.HasPrefix(query, "SELECT parallel FROM v$instance"):
+			if parallel == "" {
+				return nil, errors.New("ORA-00942: table or view does not exist")
+			}
+			return row("PARALLEL", parallel)
+		case strings.Contains(query, "SYS_CONTEXT('USERENV', 'CON_ID')"):
+			if conName == "" {
+				return nil, errors.New("ORA-02003: invalid USERENV parameter")
+			}
+			return &fakeRows{
+				columns: []string{"CON_ID", "CON_NAME"},
+				values:  [][]driver.Value{{conID, conName}},
+			}, nil
+		}
+		return &fakeRows{}, nil
+	}
+}
+
+func TestDetectServerInfo(t *testing.T) {
+	type testCase struct {
+		versionFull string
+		version     string
+		parallel    string
+		conID       int64
+		conName     string
+		expected    serverInfo
+		expectErr   bool
+	}
+
+	tests := map[string]testCase{
+		"19c RAC in a pluggable database": {
+			versionFull: "19.12.0.0.0",
+			version:     "19.0.0.0.0",
+			parallel:    "YES",
+			conID:       3,
+			conName:     "pdb1",
+			expected: serverInfo{
+				version:   serverVersion{major: 19, minor: 12},
+				rac:       true,
+				racKnown:  true,
+				cdb:       true,
+				container: "PDB1",
+			},
+		},
+		"21c single instance in the root container": {
+			versionFull: "21.3.0.0.0",
+			version:     "21.0.0.0.0",
+			parallel:    "NO",
+			conID:       1,
+			conName:     "CDB$ROOT",
+			expected: serverInfo{
+				version:   serverVersion{major: 21, minor: 3},
+				racKnown:  true,
+				cdb:       true,
+				container: rootContainer,
+			},
+		},
+		"12.1 non-CDB without access to v$instance": {
+			version: "12.1.0.2.0",
+			conID:   0,
+			conName: "NON_CDB",
+			expected: serverInfo{
+				version: serverVersion{major: 12, minor: 1},
+			},
+		},
+		"11g": {
+			version:  "11.2.0.4.0",
+			parallel: "NO",
+			expected: serverInfo{
+				version:  serverVersion{major: 11, minor: 2},
+				racKnown: true,
+			},
+		},
+		"version unknown": {
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, nil)
+			fdb.query = fakeServer(test.versionFull, test.version, test.parallel, test.conID, test.conName)
+
+			conn, err := db.getConnection(context.Background())
+			if err != nil {
+				t.Fatalf("failed to get connection: %s", err)
+			}
+
+			actual, err := detectServerInfo(context.Background(), conn)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("Actual: %+v\nExpected: %+v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_ServerInfoDefaults(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+	fdb.query = fakeServer("19.12.0.0.0", "19.0.0.0.0", "NO", 1, "CDB$ROOT")
+
+	resp, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "role",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}" CONTAINER = ALL`,
+				`COMMENT ON TABLE dual IS '{{server_version}} {{container}}'`,
+			},
+		},
+		Password: "98yq3thgnakjsfhjkl",
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	// Users created in the root container need to be common users
+	if !strings.HasPrefix(resp.Username, commonUserPrefix) {
+		t.Fatalf("expected a common username, got %q", resp.Username)
+	}
+
+	statements := fdb.statements()
+	expected := `COMMENT ON TABLE dual IS '19.12 CDB$ROOT'`
+	if statements[len(statements)-1] != expected {
+		t.Fatalf("Actual: %q\nExpected: %q", statements[len(statements)-1], expected)
+	}
+}
+
+func TestOracle_ServerInfoRetry(t *testing.T) {
+	db, fdb := newFakeOracle(t, nil)
+	logs := &bytes.Buffer{}
+	db.logger = hclog.New(&hclog.LoggerOptions{
+		Output:     logs,
+		JSONFormat: true,
+	})
+	detections := 0
+	fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+		if strings.HasPrefix(query, "SELECT version_full FROM product_component_version") {
+			detections++
+		}
+		return nil, errors.New("ORA-00942: table or view does not exist")
+	}
+
+	sqlDB, err := db.getConnection(context.Background())
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	// A failed detection is logged once and not repeated right away
+	for i := 0; i < 3; i++ {
+		if server := db.getServerInfo(context.Background(), sqlDB); server != (serverInfo{}) {
+			t.Fatalf("Actual: %+v\nExpected: %+v", server, serverInfo{})
+		}
+	}
+	if detections != 1 {
+		t.Fatalf("Actual: %d detections\nExpected: 1 detection", detections)
+	}
+	if !strings.Contains(logs.String(), "unable to detect the database server") {
+		t.Fatalf("expected the failed detection to be logged, got %s", logs)
+	}
+
+	// Once the retry interval is over, the server is probed again
+	db.serverRetryAt = time.Now().Add(-time.Second)
+	fdb.query = fakeServer("19.12.0.0.0", "19.0.0.0.0", "NO", 0, "")
+	server := db.getServerInfo(context.Background(), sqlDB)
+	if server.version != (serverVersion{major: 19, minor: 12}) {
+		t.Fatalf("Actual: %+v\nExpected: 19.12", server.version)
+	}
+}
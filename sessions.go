@@ -0,0 +1,224 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// sessionTerminationKillImmediate kills the sessions of revoked users right away, rolling back their
+	// transactions.
+	sessionTerminationKillImmediate = "kill_immediate"
+
+	// sessionTerminationDisconnectPostTransaction disconnects the sessions of revoked users once their current
+	// transaction ends.
+	sessionTerminationDisconnectPostTransaction = "disconnect_post_transaction"
+
+	// sessionTerminationLockThenWait locks the account of revoked users, gives their sessions time to end by
+	// themselves, and kills the rest.
+	sessionTerminationLockThenWait = "lock_then_wait"
+
+	// defaultSessionTerminationTimeout is how long DeleteUser waits for killed sessions to go away,
+	// and keeps retrying DROP USER while the user is still connected.
+	defaultSessionTerminationTimeout = 30 * time.Second
+
+	initialRetryInterval = 100 * time.Millisecond
+	maxRetryInterval     = 2 * time.Second
+)
+
+// lockUserSQL locks the account of the user bound to :1. DDL can't take bind variables, so the name is quoted
+// by DBMS_ASSERT as it is, which has to be the name the user is stored under, see storedUsername.
+const lockUserSQL = `BEGIN
+  EXECUTE IMMEDIATE 'ALTER USER ' || DBMS_ASSERT.ENQUOTE_NAME(:1, FALSE) || ' ACCOUNT LOCK';
+END;`
+
+var sessionTerminationModes = map[string]bool{
+	sessionTerminationKillImmediate:             true,
+	sessionTerminationDisconnectPostTransaction: true,
+	sessionTerminationLockThenWait:              true,
+}
+
+// sessionFilter selects the sessions of a user, optionally only those in one container.
+type sessionFilter struct {
+	username string
+
+	// conID is the ID of the container, or 0 for all containers.
+	conID int
+}
+
+// where returns the condition of the filter and its bind variables. The username is never part of the SQL, so
+// that it can't alter the query whatever it contains.
+func (f sessionFilter) where() (string, []interface{}) {
+	if f.conID == 0 {
+		return `username = UPPER(:1)`, []interface{}{f.username}
+	}
+	return `username = UPPER(:1) AND con_id = :2`, []interface{}{f.username, f.conID}
+}
+
+// sessionTerminationDeadline returns the time until which DeleteUser waits for the sessions of the user to end.
+func (o *Oracle) sessionTerminationDeadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(o.sessionTerminationTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// waitForSessions polls until the user has no sessions left or the deadline passes. Killed sessions remain
+// in gv$session with a KILLED status until the instance has cleaned them up, which can take a while on RAC.
+// Running out of time isn't an error: DROP USER is retried until the same deadline anyway.
+func (o *Oracle) waitForSessions(ctx context.Context, db *sql.DB, server serverInfo, filter sessionFilter, deadline time.Time) error {
+	return retryUntil(ctx, deadline, func() (bool, error) {
+		count, err := countSessions(ctx, db, server, filter)
+		if err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	})
+}
+
+// drainSessions locks the account of the user so that no new sessions are opened, and waits for the existing
+// ones to end by themselves for up to half of the time left until the deadline. The other half is left for
+// killing the remaining sessions and dropping the user.
+func (o *Oracle) drainSessions(ctx context.Context, db *sql.DB, server serverInfo, container string, filter sessionFilter, deadline time.Time) error {
+	gone := false
+	err := withContainer(ctx, db, container, func(conn *sql.Conn) error {
+		name, exists, err := storedUsername(ctx, conn, filter.username)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			gone = true
+			return nil
+		}
+		_, err = conn.ExecContext(ctx, lockUserSQL, name)
+		err = classifyError(err)
+		if errors.Is(err, ErrUserNotFound) {
+			// The user was dropped in the meantime
+			gone = true
+			return nil
+		}
+		return err
+	})
+	if gone {
+		// Without a user, there are no sessions to wait for
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	drainDeadline := time.Now().Add(time.Until(deadline) / 2)
+	return o.waitForSessions(ctx, db, server, filter, drainDeadline)
+}
+
+// terminateSessionStatement returns the statement that terminates the session in the given mode. Sessions found
+// in gv$session are addressed on their instance.
+func terminateSessionStatement(session terminatedSession, mode string, cluster bool) string {
+	id := fmt.Sprintf("%d,%d", session.sid, session.serial)
+	if cluster {
+		id += fmt.Sprintf(",@%d", session.instID)
+	}
+
+	if mode == sessionTerminationDisconnectPostTransaction {
+		return fmt.Sprintf(`ALTER SYSTEM DISCONNECT SESSION '%s' POST_TRANSACTION`, id)
+	}
+	return fmt.Sprintf(`ALTER SYSTEM KILL SESSION '%s' IMMEDIATE`, id)
+}
+
+// countSessions returns the number of sessions of the user on all instances. On a single instance database,
+// or if it isn't known whether the database is clustered and gv$session can't be queried, only the local
+// instance is checked.
+func countSessions(ctx context.Context, db *sql.DB, server serverInfo, filter sessionFilter) (int, error) {
+	where, args := filter.where()
+
+	var count int
+	if server.rac || !server.racKnown {
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM gv$session WHERE `+where, args...).Scan(&count)
+		if err == nil || server.rac {
+			return count, err
+		}
+	}
+
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM v$session WHERE `+where, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// retryUntil calls fn until it reports that it doesn't need to be retried, the deadline passes or ctx is
+// done, backing off exponentially between attempts. The error of the last attempt is returned.
+func retryUntil(ctx context.Context, deadline time.Time, fn func() (retry bool, err error)) error {
+	interval := initialRetryInterval
+	for {
+		retry, err := fn()
+		if !retry {
+			return err
+		}
+
+		wait := min(interval, time.Until(deadline))
+		if wait <= 0 {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		interval = min(interval*2, maxRetryInterval)
+	}
+}
+
+const (
+	// killSessionsBlock kills the sessions of the user bound to :1 on every instance. Sessions that already
+	// ended (ORA-00030) or are already marked for kill (ORA-00031) are ignored.
+	killSessionsBlock = `DECLARE
+  v_username VARCHAR2(128) := UPPER(:1);
+  v_remaining NUMBER;
+BEGIN
+  FOR s IN (SELECT inst_id, sid, serial# AS serial FROM gv$session WHERE username = v_username) LOOP
+    BEGIN
+      EXECUTE IMMEDIATE 'ALTER SYSTEM KILL SESSION ''' || s.sid || ',' || s.serial || ',@' || s.inst_id || ''' IMMEDIATE';
+    EXCEPTION
+      WHEN OTHERS THEN
+        IF SQLCODE NOT IN (-30, -31) THEN
+          RAISE;
+        END IF;
+    END;
+  END LOOP;
+%sEND;`
+
+	// waitForSessionsLoop waits up to 10 seconds for the killed sessions to go away. DBMS_SESSION.SLEEP is
+	// only available from 18c; on older releases, DROP USER is retried from the plugin instead of requiring
+	// the EXECUTE privilege on DBMS_LOCK.
+	waitForSessionsLoop = `  FOR i IN 1 .. 20 LOOP
+    SELECT COUNT(*) INTO v_remaining FROM gv$session WHERE username = v_username;
+    EXIT WHEN v_remaining = 0;
+    DBMS_SESSION.SLEEP(0.5);
+  END LOOP;
+`
+)
+
+// killSessionsStatement returns the block that kills the sessions of a user for the given server version.
+func killSessionsStatement(majorVersion int) string {
+	if majorVersion >= 18 {
+		return fmt.Sprintf(killSessionsBlock, waitForSessionsLoop)
+	}
+	return fmt.Sprintf(killSessionsBlock, "")
+}
+
+// isKillSessionsStatement reports whether the statement is one of the default blocks that take the username as
+// a bind variable rather than through the {{username}} template.
+func isKillSessionsStatement(query string) bool {
+	return query == killSessionsStatement(0) || query == killSessionsStatement(18)
+}
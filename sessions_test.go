@@ -0,0 +1,719 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func TestRetryUntil(t *testing.T) {
+	errRetry := errors.New("retry")
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		attempts := 0
+		err := retryUntil(context.Background(), time.Now().Add(time.Minute), func() (bool, error) {
+			attempts++
+			if attempts < 3 {
+				return true, errRetry
+			}
+			return false, nil
+		})
+		if err != nil {
+			t.Fatalf("no error expected, got: %s", err)
+		}
+		if attempts != 3 {
+			t.Fatalf("Actual: %d\nExpected: 3", attempts)
+		}
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		start := time.Now()
+		err := retryUntil(context.Background(), start.Add(300*time.Millisecond), func() (bool, error) {
+			return true, errRetry
+		})
+		if !errors.Is(err, errRetry) {
+			t.Fatalf("Actual: %v\nExpected: %v", err, errRetry)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("retried for %s past the deadline", elapsed)
+		}
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		attempts := 0
+		err := retryUntil(ctx, time.Now().Add(time.Minute), func() (bool, error) {
+			attempts++
+			return true, errRetry
+		})
+		if !errors.Is(err, errRetry) {
+			t.Fatalf("Actual: %v\nExpected: %v", err, errRetry)
+		}
+		if attempts != 1 {
+			t.Fatalf("Actual: %d\nExpected: 1", attempts)
+		}
+	})
+}
+
+// fakeSessions emulates a user with one session that lingers in gv$session for the given number of polls
+// after being killed.
+func fakeSessions(lingering int) func(query string, args []driver.Value) (driver.Rows, error) {
+	var polls int32
+	return func(query string, args []driver.Value) (driver.Rows, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT COUNT(*) FROM gv$session"):
+			count := int64(0)
+			if int(atomic.AddInt32(&polls, 1)) <= lingering {
+				count = 1
+			}
+			return &fakeRows{
+				columns: []string{"COUNT(*)"},
+				values:  [][]driver.Value{{count}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT username FROM all_users"):
+			return &fakeRows{
+				columns: []string{"USERNAME"},
+				values:  [][]driver.Value{{args[0]}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT username FROM all_users"):
+			return &fakeRows{
+				columns: []string{"USERNAME"},
+				values:  [][]driver.Value{{args[0]}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT inst_id, sid, serial#, machine, program FROM gv$session"):
+			return &fakeRows{
+				columns: []string{"INST_ID", "SID", "SERIAL#", "MACHINE", "PROGRAM"},
+				values:  [][]driver.Value{{int64(1), int64(42), int64(4242), "app01", "java@app01"}},
+			}, nil
+		}
+		return &fakeRows{}, nil
+	}
+}
+
+func TestOracle_DeleteUserWaitsForSessions(t *testing.T) {
+	type testCase struct {
+		lingering    int
+		dropFailures int32
+		timeout      string
+		expectedIs   error
+	}
+
+	tests := map[string]testCase{
+		"sessions end right away": {
+			timeout: "30s",
+		},
+		"sessions linger": {
+			lingering: 3,
+			timeout:   "30s",
+		},
+		"drop retried while connected": {
+			dropFailures: 2,
+			timeout:      "30s",
+		},
+		"sessions never end": {
+			lingering:    1000,
+			dropFailures: 1000,
+			timeout:      "1s",
+			expectedIs:   ErrUserConnected,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"session_termination_timeout": test.timeout,
+			})
+
+			var drops int32
+			fdb.query = fakeSessions(test.lingering)
+			fdb.exec = func(query string, _ []driver.Value) error {
+				if strings.HasPrefix(query, "DROP USER") && atomic.AddInt32(&drops, 1) <= test.dropFailures {
+					return errors.New("ORA-01940: cannot drop a user that is currently connected")
+				}
+				return nil
+			}
+
+			start := time.Now()
+			_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+				Username: "V_TEST",
+			})
+			if test.expectedIs == nil && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if test.expectedIs != nil && !errors.Is(err, test.expectedIs) {
+				t.Fatalf("Actual: %v\nExpected: %v", err, test.expectedIs)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Fatalf("DeleteUser took %s", elapsed)
+			}
+
+			killed := false
+			for _, stmt := range fdb.statements() {
+				if stmt == `ALTER SYSTEM KILL SESSION '42,4242,@1' IMMEDIATE` {
+					killed = true
+				}
+			}
+			if !killed {
+				t.Fatalf("session was not killed: %v", fdb.statements())
+			}
+		})
+	}
+}
+
+func TestDefaultSessionRevocationStatements(t *testing.T) {
+	type testCase struct {
+		majorVersion  int
+		expectedSleep bool
+	}
+
+	tests := map[string]testCase{
+		"unknown version": {
+			majorVersion:  0,
+			expectedSleep: false,
+		},
+		"12c": {
+			majorVersion:  12,
+			expectedSleep: false,
+		},
+		"18c": {
+			majorVersion:  18,
+			expectedSleep: true,
+		},
+		"19c": {
+			majorVersion:  19,
+			expectedSleep: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statements := defaultSessionRevocationStatements(test.majorVersion)
+			block := statements[1]
+
+			actual := splitStatements(block)
+			if len(actual) != 1 || actual[0] != block {
+				t.Fatalf("block was not kept as a single statement: %q", actual)
+			}
+
+			actual = splitStatements(strings.Join(statements, ";\n"))
+			if len(actual) != len(statements) {
+				t.Fatalf("Actual: %q\nExpected: %q", actual, statements)
+			}
+
+			if !isKillSessionsStatement(block) {
+				t.Fatalf("block is not recognized as the kill sessions statement")
+			}
+			if strings.Contains(block, "{{") {
+				t.Fatalf("block must bind the username instead of templating it: %s", block)
+			}
+			if strings.Contains(strings.ToUpper(block), "DBMS_LOCK") {
+				t.Fatalf("block must not depend on DBMS_LOCK: %s", block)
+			}
+			if sleep := strings.Contains(block, "DBMS_SESSION.SLEEP"); sleep != test.expectedSleep {
+				t.Fatalf("Actual sleep: %t\nExpected sleep: %t", sleep, test.expectedSleep)
+			}
+		})
+	}
+}
+
+func TestOracle_DeleteUserBindsUsername(t *testing.T) {
+	db, fdb := newFakeOracle(t, map[string]interface{}{
+		"disconnect_sessions":         false,
+		"session_termination_timeout": "0s",
+	})
+
+	fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+		if strings.Contains(query, "product_component_version") {
+			return &fakeRows{
+				columns: []string{"VERSION"},
+				values:  [][]driver.Value{{"19.0.0.0.0"}},
+			}, nil
+		}
+		return &fakeRows{}, nil
+	}
+
+	var boundUsername driver.Value
+	fdb.exec = func(query string, args []driver.Value) error {
+		if isKillSessionsStatement(query) && len(args) == 1 {
+			boundUsername = args[0]
+		}
+		return nil
+	}
+
+	_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+		Username: "V_TEST",
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	statements := fdb.statements()
+	expected := defaultSessionRevocationStatements(19)
+	expected[0] = `ALTER USER V_TEST ACCOUNT LOCK`
+	expected[2] = `DROP USER V_TEST`
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("Actual: %q\nExpected: %q", statements, expected)
+	}
+	if boundUsername != "V_TEST" {
+		t.Fatalf("Actual: %v\nExpected: V_TEST", boundUsername)
+	}
+}
+
+func TestOracle_DisconnectSessionLogsTerminatedSessions(t *testing.T) {
+	type testCase struct {
+		clusterErr         error
+		killErr            error
+		expectedTerminated []terminatedSession
+		expectedFallback   bool
+	}
+
+	tests := map[string]testCase{
+		"killed on all instances": {
+			expectedTerminated: []terminatedSession{
+				{instID: 2, sid: 42, serial: 4242, machine: "app01", program: "java@app01", mode: sessionTerminationKillImmediate},
+			},
+		},
+		"fallback to the local instance": {
+			clusterErr: errors.New("ORA-00942: table or view does not exist"),
+			expectedTerminated: []terminatedSession{
+				{instID: 1, sid: 42, serial: 4242, machine: "app01", program: "java@app01", mode: sessionTerminationKillImmediate},
+			},
+			expectedFallback: true,
+		},
+		"session marked for kill": {
+			killErr: errors.New("ORA-00031: session marked for kill"),
+			expectedTerminated: []terminatedSession{
+				{instID: 2, sid: 42, serial: 4242, machine: "app01", program: "java@app01", mode: sessionTerminationKillImmediate},
+			},
+		},
+		"session already gone": {
+			killErr:            errors.New("ORA-00030: User session ID does not exist."),
+			expectedTerminated: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"session_termination_timeout": "0s",
+			})
+			logs := &bytes.Buffer{}
+			db.logger = hclog.New(&hclog.LoggerOptions{
+				Output:     logs,
+				JSONFormat: true,
+			})
+
+			fdb.query = func(query string, _ []driver.Value) (driver.Rows, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT inst_id, sid, serial#, machine, program FROM gv$session"):
+					if test.clusterErr != nil {
+						return nil, test.clusterErr
+					}
+					return &fakeRows{
+						columns: []string{"INST_ID", "SID", "SERIAL#", "MACHINE", "PROGRAM"},
+						values:  [][]driver.Value{{int64(2), int64(42), int64(4242), "app01", "java@app01"}},
+					}, nil
+				case strings.HasPrefix(query, "SELECT SYS_CONTEXT('USERENV', 'INSTANCE'), sid, serial#, machine, program FROM v$session"):
+					return &fakeRows{
+						columns: []string{"INSTANCE", "SID", "SERIAL#", "MACHINE", "PROGRAM"},
+						values:  [][]driver.Value{{"1", int64(42), int64(4242), "app01", "java@app01"}},
+					}, nil
+				case strings.HasPrefix(query, "SELECT COUNT(*)"):
+					return &fakeRows{
+						columns: []string{"COUNT(*)"},
+						values:  [][]driver.Value{{int64(0)}},
+					}, nil
+				}
+				return &fakeRows{}, nil
+			}
+			fdb.exec = func(query string, _ []driver.Value) error {
+				if strings.HasPrefix(query, "ALTER SYSTEM KILL SESSION") {
+					return test.killErr
+				}
+				return nil
+			}
+
+			conn, err := db.getConnection(context.Background())
+			if err != nil {
+				t.Fatalf("failed to get connection: %s", err)
+			}
+			terminated, err := db.disconnectSession(context.Background(), conn, serverInfo{}, "V_TEST", "", time.Now())
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if !reflect.DeepEqual(terminated, test.expectedTerminated) {
+				t.Fatalf("Actual: %+v\nExpected: %+v", terminated, test.expectedTerminated)
+			}
+
+			_, err = db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+				Username: "V_TEST",
+			})
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			fallback := strings.Contains(logs.String(), "unable to terminate sessions on all instances, falling back to the local instance")
+			if fallback != test.expectedFallback {
+				t.Fatalf("Actual fallback: %t\nExpected fallback: %t\nLogs: %s", fallback, test.expectedFallback, logs)
+			}
+			if test.expectedFallback && !strings.Contains(logs.String(), "ORA-00942") {
+				t.Fatalf("fallback reason was not logged: %s", logs)
+			}
+
+			loggedSessions := strings.Count(logs.String(), `"terminated session of revoked user"`)
+			if loggedSessions != len(test.expectedTerminated) {
+				t.Fatalf("Actual: %d terminated sessions logged\nExpected: %d\nLogs: %s", loggedSessions, len(test.expectedTerminated), logs)
+			}
+			for _, session := range test.expectedTerminated {
+				if !strings.Contains(logs.String(), `"machine":"`+session.machine+`"`) || !strings.Contains(logs.String(), `"program":"`+session.program+`"`) {
+					t.Fatalf("session details were not logged: %s", logs)
+				}
+			}
+		})
+	}
+}
+
+func TestTerminateSessionStatement(t *testing.T) {
+	type testCase struct {
+		mode     string
+		cluster  bool
+		expected string
+	}
+
+	session := terminatedSession{instID: 2, sid: 42, serial: 4242}
+	tests := map[string]testCase{
+		"kill on cluster": {
+			mode:     sessionTerminationKillImmediate,
+			cluster:  true,
+			expected: `ALTER SYSTEM KILL SESSION '42,4242,@2' IMMEDIATE`,
+		},
+		"kill local": {
+			mode:     sessionTerminationKillImmediate,
+			expected: `ALTER SYSTEM KILL SESSION '42,4242' IMMEDIATE`,
+		},
+		"disconnect on cluster": {
+			mode:     sessionTerminationDisconnectPostTransaction,
+			cluster:  true,
+			expected: `ALTER SYSTEM DISCONNECT SESSION '42,4242,@2' POST_TRANSACTION`,
+		},
+		"disconnect local": {
+			mode:     sessionTerminationDisconnectPostTransaction,
+			expected: `ALTER SYSTEM DISCONNECT SESSION '42,4242' POST_TRANSACTION`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := terminateSessionStatement(session, test.mode, test.cluster)
+			if actual != test.expected {
+				t.Fatalf("Actual: %s\nExpected: %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestOracle_SessionTerminationMode(t *testing.T) {
+	type testCase struct {
+		mode         string
+		lingering    int
+		expectErr    bool
+		expectedLock bool
+		expectedKill string
+		expectNoKill bool
+	}
+
+	tests := map[string]testCase{
+		"default": {
+			mode:         "",
+			expectedKill: `ALTER SYSTEM KILL SESSION '42,4242,@1' IMMEDIATE`,
+		},
+		"kill_immediate": {
+			mode:         sessionTerminationKillImmediate,
+			expectedKill: `ALTER SYSTEM KILL SESSION '42,4242,@1' IMMEDIATE`,
+		},
+		"disconnect_post_transaction": {
+			mode:         sessionTerminationDisconnectPostTransaction,
+			expectedKill: `ALTER SYSTEM DISCONNECT SESSION '42,4242,@1' POST_TRANSACTION`,
+		},
+		"lock_then_wait sessions end": {
+			mode:         sessionTerminationLockThenWait,
+			lingering:    2,
+			expectedLock: true,
+			expectNoKill: true,
+		},
+		"lock_then_wait sessions linger": {
+			mode:         sessionTerminationLockThenWait,
+			lingering:    1000,
+			expectedLock: true,
+			expectedKill: `ALTER SYSTEM KILL SESSION '42,4242,@1' IMMEDIATE`,
+		},
+		"invalid": {
+			mode:      "kill_eventually",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"session_termination_mode":    test.mode,
+				"session_termination_timeout": "1s",
+			}
+			if test.expectErr {
+				db := new()
+				config["connection_url"] = "system/oracle@localhost:1521/xe"
+				_, err := db.Initialize(context.Background(), dbplugin.InitializeRequest{
+					Config:           config,
+					VerifyConnection: false,
+				})
+				if err == nil {
+					t.Fatalf("err expected, got nil")
+				}
+				return
+			}
+
+			db, fdb := newFakeOracle(t, config)
+
+			// Sessions only linger while they are polled, so that they end during the drain if lingering is
+			// small and are still there when killed otherwise. The kill then ends them.
+			var polls, killed int32
+			fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT COUNT(*) FROM gv$session"):
+					count := int64(0)
+					if atomic.LoadInt32(&killed) == 0 && int(atomic.AddInt32(&polls, 1)) <= test.lingering {
+						count = 1
+					}
+					return &fakeRows{
+						columns: []string{"COUNT(*)"},
+						values:  [][]driver.Value{{count}},
+					}, nil
+				case test.lingering > 0 && int(atomic.LoadInt32(&polls)) > test.lingering:
+					// The sessions ended during the drain
+					return &fakeRows{}, nil
+				}
+				return fakeSessions(0)(query, args)
+			}
+			fdb.exec = func(query string, _ []driver.Value) error {
+				if strings.HasPrefix(query, "ALTER SYSTEM") {
+					atomic.StoreInt32(&killed, 1)
+				}
+				return nil
+			}
+
+			_, err := db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+				Username: "V_TEST",
+			})
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			statements := fdb.statements()
+			locked, kills := false, []string{}
+			for _, stmt := range statements {
+				if stmt == lockUserSQL && len(kills) == 0 {
+					locked = true
+				}
+				if strings.HasPrefix(stmt, "ALTER SYSTEM") {
+					kills = append(kills, stmt)
+				}
+			}
+			if locked != test.expectedLock {
+				t.Fatalf("Actual locked: %t\nExpected locked: %t\nStatements: %q", locked, test.expectedLock, statements)
+			}
+			if test.expectNoKill && len(kills) > 0 {
+				t.Fatalf("no sessions should have been killed: %q", statements)
+			}
+			if !test.expectNoKill && (len(kills) != 1 || kills[0] != test.expectedKill) {
+				t.Fatalf("Actual: %q\nExpected: %q", kills, test.expectedKill)
+			}
+		})
+	}
+}
+
+func TestOracle_DrainSessionsLocksStoredUsername(t *testing.T) {
+	type testCase struct {
+		username   string
+		storedName string
+
+		expectedLock bool
+	}
+
+	tests := map[string]testCase{
+		"unquoted user": {
+			username:     "v_test",
+			storedName:   "V_TEST",
+			expectedLock: true,
+		},
+		"quoted lowercase user": {
+			username:     "v_test",
+			storedName:   "v_test",
+			expectedLock: true,
+		},
+		"missing user": {
+			username: "v_test",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := newFakeOracle(t, map[string]interface{}{
+				"session_termination_mode":    sessionTerminationLockThenWait,
+				"session_termination_timeout": "0s",
+			})
+			fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+				if strings.HasPrefix(query, "SELECT username FROM all_users") {
+					if test.storedName == "" {
+						return &fakeRows{}, nil
+					}
+					return &fakeRows{
+						columns: []string{"USERNAME"},
+						values:  [][]driver.Value{{test.storedName}},
+					}, nil
+				}
+				return fakeSessions(0)(query, args)
+			}
+			var locked []driver.Value
+			fdb.exec = func(query string, args []driver.Value) error {
+				if query == lockUserSQL {
+					locked = args
+				}
+				return nil
+			}
+
+			filter := sessionFilter{username: test.username}
+			err := db.drainSessions(context.Background(), mustConnection(t, db), serverInfo{}, "", filter, time.Now())
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			if !test.expectedLock {
+				if locked != nil {
+					t.Fatalf("expected no lock, got %v", locked)
+				}
+				return
+			}
+			expected := []driver.Value{test.storedName}
+			if !reflect.DeepEqual(locked, expected) {
+				t.Fatalf("Actual: %v\nExpected: %v", locked, expected)
+			}
+		})
+	}
+}
+
+func TestSessionFilter(t *testing.T) {
+	type testCase struct {
+		filter        sessionFilter
+		expectedWhere string
+		expectedArgs  []interface{}
+	}
+
+	tests := map[string]testCase{
+		"all containers": {
+			filter:        sessionFilter{username: "V_TEST"},
+			expectedWhere: `username = UPPER(:1)`,
+			expectedArgs:  []interface{}{"V_TEST"},
+		},
+		"one container": {
+			filter:        sessionFilter{username: "V_TEST", conID: 3},
+			expectedWhere: `username = UPPER(:1) AND con_id = :2`,
+			expectedArgs:  []interface{}{"V_TEST", 3},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			where, args := test.filter.where()
+			if where != test.expectedWhere {
+				t.Fatalf("Actual: %s\nExpected: %s", where, test.expectedWhere)
+			}
+			if !reflect.DeepEqual(args, test.expectedArgs) {
+				t.Fatalf("Actual: %v\nExpected: %v", args, test.expectedArgs)
+			}
+		})
+	}
+}
+
+func TestOracle_DisconnectSessionHostileUsernames(t *testing.T) {
+	usernames := map[string]string{
+		"closing quote":   `V_TEST') OR 1=1 --`,
+		"union":           `V_TEST' UNION SELECT inst_id, sid, serial#, machine, program FROM gv$session --`,
+		"subquery":        `V_TEST'||(SELECT password FROM sys.user$ WHERE name = 'SYS')||'`,
+		"statement":       `V_TEST'); EXECUTE IMMEDIATE 'DROP USER SYSTEM CASCADE'; --`,
+		"double quote":    `V_TEST" ACCOUNT UNLOCK --`,
+		"placeholder":     `{{username}}`,
+		"newline comment": "V_TEST'\n--",
+	}
+
+	for _, mode := range []string{sessionTerminationKillImmediate, sessionTerminationLockThenWait} {
+		for name, username := range usernames {
+			t.Run(mode+" "+name, func(t *testing.T) {
+				db, fdb := newFakeOracle(t, map[string]interface{}{
+					"session_termination_mode":    mode,
+					"session_termination_timeout": "0s",
+				})
+
+				var mu sync.Mutex
+				var queries []string
+				fdb.query = func(query string, args []driver.Value) (driver.Rows, error) {
+					mu.Lock()
+					queries = append(queries, query)
+					mu.Unlock()
+
+					if strings.Contains(query, "$session WHERE") && (len(args) == 0 || args[0] != username) {
+						t.Errorf("username not bound to %q: %v", query, args)
+					}
+					return fakeSessions(0)(query, args)
+				}
+				fdb.exec = func(query string, args []driver.Value) error {
+					if query == lockUserSQL && (len(args) != 1 || args[0] != username) {
+						t.Errorf("username not bound to %q: %v", query, args)
+					}
+					return nil
+				}
+
+				_, err := db.disconnectSession(context.Background(), mustConnection(t, db), serverInfo{}, username, "", time.Now())
+				if err != nil {
+					t.Fatalf("no error expected, got: %s", err)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, stmt := range append(queries, fdb.statements()...) {
+					if strings.Contains(stmt, "V_TEST") || strings.Contains(stmt, "{{") {
+						t.Fatalf("username was interpolated into %q", stmt)
+					}
+				}
+				if len(queries) == 0 {
+					t.Fatalf("no sessions were looked up")
+				}
+			})
+		}
+	}
+}
+
+// mustConnection returns the connection pool of the plugin.
+func mustConnection(t *testing.T, db *Oracle) *sql.DB {
+	t.Helper()
+
+	conn, err := db.getConnection(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get connection: %s", err)
+	}
+	return conn
+}
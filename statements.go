@@ -0,0 +1,474 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// statementKind describes how the end of a statement is detected.
+type statementKind int
+
+const (
+	// kindUnknown is used until enough of the statement has been read to classify it.
+	kindUnknown statementKind = iota
+
+	// kindSQL is a plain SQL statement terminated by a semicolon.
+	kindSQL
+
+	// kindAnonymousBlock is a DECLARE/BEGIN block. It ends at the semicolon following
+	// the END that closes the outermost block.
+	kindAnonymousBlock
+
+	// kindStoredUnit is a CREATE [OR REPLACE] PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE/...
+	// statement. As in SQL*Plus, it ends at a line containing only "/" or at the end of
+	// the input.
+	kindStoredUnit
+
+	// kindCommand is a SQL*Plus DEFINE command, which ends at the end of the line.
+	kindCommand
+)
+
+// defineRegex matches a `DEFINE name = value` command. The value may be quoted, and ends at the end of the line,
+// as the statements following the command aren't split from it when split_statements is false.
+var defineRegex = regexp.MustCompile(`(?i)\ADEFINE[ \t]+([a-z][a-z0-9_$#]*)[ \t]*=[ \t]*(.*?)[ \t\r]*;?[ \t\r]*(?:\n|\z)`)
+
+// createModifiers are the words that may appear between CREATE and the type of object
+// being created.
+var createModifiers = map[string]bool{
+	"OR":             true,
+	"REPLACE":        true,
+	"EDITIONABLE":    true,
+	"NONEDITIONABLE": true,
+	"AND":            true,
+	"COMPILE":        true,
+	"RESOLVE":        true,
+	"NOFORCE":        true,
+}
+
+// storedUnitTypes are the object types whose CREATE statement contains PL/SQL.
+var storedUnitTypes = map[string]bool{
+	"PROCEDURE": true,
+	"FUNCTION":  true,
+	"PACKAGE":   true,
+	"TRIGGER":   true,
+	"TYPE":      true,
+	"LIBRARY":   true,
+	"JAVA":      true,
+}
+
+// blockLevel is a level of nesting within an anonymous PL/SQL block.
+type blockLevel struct {
+	// awaitingBegin is true for DECLARE sections and subprogram declarations whose
+	// BEGIN has not been seen yet. That BEGIN opens the body of this level rather
+	// than a new, nested level.
+	awaitingBegin bool
+}
+
+// statementSplitter splits a string containing SQL statements and PL/SQL blocks into
+// individual statements. It understands string literals (including q'[...]' alternative
+// quoting), quoted identifiers, single and multi-line comments, PL/SQL block nesting,
+// and SQL*Plus-style "/" terminators.
+type statementSplitter struct {
+	input      string
+	pos        int
+	statements []string
+
+	// State of the statement currently being read
+	start       int
+	kind        statementKind
+	words       []string
+	significant bool
+
+	// Anonymous block state
+	levels           []blockLevel
+	opened           bool
+	pendingEnd       bool
+	subprogramHeader bool
+	afterEnd         bool
+}
+
+// splitStatements splits the provided string into individual statements. Plain SQL
+// statements are returned without their terminating semicolon, while PL/SQL blocks are
+// returned with the trailing semicolon they require. Empty and comment-only statements
+// are dropped.
+func splitStatements(input string) []string {
+	s := &statementSplitter{
+		input: input,
+	}
+	s.split()
+	return s.statements
+}
+
+func (s *statementSplitter) split() {
+	s.reset(0)
+
+	for s.pos < len(s.input) {
+		if s.atLineStart() && s.slashLine() {
+			continue
+		}
+
+		c := s.input[s.pos]
+		switch {
+		case c == '-' && s.peek(1) == '-':
+			s.skipLineComment()
+		case c == '/' && s.peek(1) == '*':
+			s.skipBlockComment()
+		case c == '\'':
+			s.significant = true
+			s.skipStringLiteral(s.pos)
+		case c == '"':
+			s.significant = true
+			s.skipQuotedIdentifier()
+		case s.alternativeQuoteAt(s.pos):
+			s.significant = true
+			s.skipAlternativeQuote()
+		case c == ';':
+			s.semicolon()
+		case isIdentifierStart(c):
+			s.significant = true
+			s.word()
+		case c == '\n' && s.kind == kindCommand:
+			s.emit(s.pos, s.pos+1)
+			s.pos++
+		case isSpace(c):
+			s.pos++
+		default:
+			s.significant = true
+			s.endPending()
+			s.pos++
+		}
+	}
+
+	s.emit(len(s.input), len(s.input))
+}
+
+// reset prepares the splitter to read a new statement starting at the given position.
+func (s *statementSplitter) reset(start int) {
+	s.start = start
+	s.kind = kindUnknown
+	s.words = nil
+	s.significant = false
+	s.levels = nil
+	s.opened = false
+	s.pendingEnd = false
+	s.subprogramHeader = false
+	s.afterEnd = false
+}
+
+// emit records the current statement as ending at end, and starts a new statement at next.
+func (s *statementSplitter) emit(end, next int) {
+	if s.significant {
+		stmt := strings.TrimSpace(s.input[s.start:end])
+		if stmt != "" {
+			s.statements = append(s.statements, stmt)
+		}
+	}
+	s.reset(next)
+}
+
+func (s *statementSplitter) peek(offset int) byte {
+	if s.pos+offset >= len(s.input) {
+		return 0
+	}
+	return s.input[s.pos+offset]
+}
+
+func (s *statementSplitter) atLineStart() bool {
+	return s.pos == 0 || s.input[s.pos-1] == '\n'
+}
+
+// slashLine checks whether the line starting at the current position contains only a
+// "/", which terminates the current statement. If it does, the statement is emitted and
+// the line is consumed.
+func (s *statementSplitter) slashLine() bool {
+	lineEnd := strings.IndexByte(s.input[s.pos:], '\n')
+	next := len(s.input)
+	if lineEnd >= 0 {
+		lineEnd += s.pos
+		next = lineEnd + 1
+	} else {
+		lineEnd = len(s.input)
+	}
+
+	if strings.TrimSpace(s.input[s.pos:lineEnd]) != "/" {
+		return false
+	}
+
+	s.emit(s.pos, next)
+	s.pos = next
+	return true
+}
+
+func (s *statementSplitter) skipLineComment() {
+	end := strings.IndexByte(s.input[s.pos:], '\n')
+	if end < 0 {
+		s.pos = len(s.input)
+		return
+	}
+	// Leave the newline in place so the next line is checked for a "/" terminator
+	s.pos += end
+}
+
+func (s *statementSplitter) skipBlockComment() {
+	end := strings.Index(s.input[s.pos+2:], "*/")
+	if end < 0 {
+		s.pos = len(s.input)
+		return
+	}
+	s.pos += 2 + end + 2
+}
+
+// skipStringLiteral skips over a single-quoted string literal whose opening quote is at
+// the given position. Quotes inside the literal are escaped by doubling them.
+func (s *statementSplitter) skipStringLiteral(quote int) {
+	i := quote + 1
+	for i < len(s.input) {
+		if s.input[i] == '\'' {
+			if i+1 < len(s.input) && s.input[i+1] == '\'' {
+				i += 2
+				continue
+			}
+			s.pos = i + 1
+			return
+		}
+		i++
+	}
+	s.pos = len(s.input)
+}
+
+func (s *statementSplitter) skipQuotedIdentifier() {
+	end := strings.IndexByte(s.input[s.pos+1:], '"')
+	if end < 0 {
+		s.pos = len(s.input)
+		return
+	}
+	s.pos += 1 + end + 1
+}
+
+// alternativeQuoteAt reports whether a q'...' or nq'...' literal starts at the given position.
+func (s *statementSplitter) alternativeQuoteAt(i int) bool {
+	if i > 0 && isIdentifierPart(s.input[i-1]) {
+		return false
+	}
+	if i < len(s.input) && (s.input[i] == 'n' || s.input[i] == 'N') {
+		i++
+	}
+	return i+2 < len(s.input) &&
+		(s.input[i] == 'q' || s.input[i] == 'Q') &&
+		s.input[i+1] == '\''
+}
+
+// skipAlternativeQuote skips over a q'<delim>...<delim>' literal. The closing delimiter is
+// the matching bracket when the opening delimiter is one of [, {, < or (, and the
+// delimiter itself otherwise.
+func (s *statementSplitter) skipAlternativeQuote() {
+	i := s.pos
+	if s.input[i] == 'n' || s.input[i] == 'N' {
+		i++
+	}
+	open := s.input[i+2]
+	closing := open
+	switch open {
+	case '[':
+		closing = ']'
+	case '{':
+		closing = '}'
+	case '<':
+		closing = '>'
+	case '(':
+		closing = ')'
+	}
+
+	for j := i + 3; j+1 < len(s.input); j++ {
+		if s.input[j] == closing && s.input[j+1] == '\'' {
+			s.pos = j + 2
+			return
+		}
+	}
+	s.pos = len(s.input)
+}
+
+func (s *statementSplitter) semicolon() {
+	s.endPending()
+
+	switch s.kind {
+	case kindAnonymousBlock:
+		s.subprogramHeader = false
+		if s.opened && len(s.levels) == 0 {
+			s.emit(s.pos+1, s.pos+1)
+		}
+		s.pos++
+	case kindStoredUnit:
+		s.pos++
+	default:
+		s.emit(s.pos, s.pos+1)
+		s.pos++
+	}
+}
+
+func (s *statementSplitter) word() {
+	end := s.pos + 1
+	for end < len(s.input) && isIdentifierPart(s.input[end]) {
+		end++
+	}
+	word := strings.ToUpper(s.input[s.pos:end])
+	s.pos = end
+
+	if s.kind == kindUnknown {
+		s.classify(word)
+	}
+	if s.kind == kindAnonymousBlock {
+		s.blockKeyword(word)
+	}
+}
+
+// classify determines the kind of the current statement from its leading words.
+func (s *statementSplitter) classify(word string) {
+	s.words = append(s.words, word)
+
+	switch s.words[0] {
+	case "DECLARE", "BEGIN":
+		s.kind = kindAnonymousBlock
+	case "DEFINE":
+		s.kind = kindCommand
+	case "CREATE":
+		if len(s.words) == 1 || createModifiers[word] {
+			return
+		}
+		if storedUnitTypes[word] {
+			s.kind = kindStoredUnit
+			return
+		}
+		s.kind = kindSQL
+	default:
+		s.kind = kindSQL
+	}
+}
+
+// blockKeyword tracks the nesting of an anonymous block.
+func (s *statementSplitter) blockKeyword(word string) {
+	if s.pendingEnd {
+		s.pendingEnd = false
+		// END IF and END LOOP close constructs that don't open a level
+		if word == "IF" || word == "LOOP" {
+			s.afterEnd = false
+			return
+		}
+		s.pop()
+		s.afterEnd = true
+	} else {
+		s.afterEnd = false
+	}
+
+	switch word {
+	case "DECLARE":
+		s.push(true)
+	case "BEGIN":
+		if n := len(s.levels); n > 0 && s.levels[n-1].awaitingBegin {
+			s.levels[n-1].awaitingBegin = false
+			return
+		}
+		s.push(false)
+	case "CASE":
+		// END CASE closes the level opened by CASE
+		if !s.afterEnd {
+			s.push(false)
+		}
+	case "PROCEDURE", "FUNCTION":
+		s.subprogramHeader = true
+	case "IS", "AS":
+		if s.subprogramHeader {
+			s.subprogramHeader = false
+			s.push(true)
+		}
+	case "END":
+		s.pendingEnd = true
+	}
+}
+
+// endPending closes the level of a preceding END that wasn't followed by a word.
+func (s *statementSplitter) endPending() {
+	if s.pendingEnd {
+		s.pendingEnd = false
+		s.pop()
+	}
+	s.afterEnd = false
+}
+
+func (s *statementSplitter) push(awaitingBegin bool) {
+	s.levels = append(s.levels, blockLevel{awaitingBegin: awaitingBegin})
+	s.opened = true
+}
+
+func (s *statementSplitter) pop() {
+	if len(s.levels) > 0 {
+		s.levels = s.levels[:len(s.levels)-1]
+	}
+}
+
+func isIdentifierStart(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || c >= '0' && c <= '9' || c == '_' || c == '$' || c == '#'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// extractDefines removes SQL*Plus-style `DEFINE name = value` commands from the list of statements. The
+// defined values are returned keyed by their lower-cased name, and are used both as role-level options
+// (such as the target container) and as additional template variables.
+func extractDefines(statements []string) (map[string]string, []string, error) {
+	defines := map[string]string{}
+	remaining := []string{}
+	for _, stmt := range statements {
+		for isDefine(stmt) {
+			match := defineRegex.FindStringSubmatchIndex(stmt)
+			if match == nil {
+				line, _, _ := strings.Cut(stmt, "\n")
+				return nil, nil, fmt.Errorf("invalid DEFINE command %q: must be of the form DEFINE name = value", line)
+			}
+			defines[strings.ToLower(stmt[match[2]:match[3]])] = unquoteDefine(stmt[match[4]:match[5]])
+			stmt = strings.TrimSpace(stmt[match[1]:])
+		}
+		if stmt != "" {
+			remaining = append(remaining, stmt)
+		}
+	}
+	return defines, remaining, nil
+}
+
+func isDefine(stmt string) bool {
+	if len(stmt) < len("DEFINE") || !strings.EqualFold(stmt[:len("DEFINE")], "DEFINE") {
+		return false
+	}
+	return len(stmt) == len("DEFINE") || !isIdentifierPart(stmt[len("DEFINE")])
+}
+
+func unquoteDefine(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '\'' || first == '"') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// mergeDefines adds the defined values to the template variables without overriding the
+// variables set by the plugin.
+func mergeDefines(variables map[string]string, defines map[string]string) map[string]string {
+	for k, v := range defines {
+		if _, ok := variables[k]; !ok {
+			variables[k] = v
+		}
+	}
+	return variables
+}
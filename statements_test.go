@@ -0,0 +1,352 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected []string
+	}
+
+	tests := map[string]testCase{
+		"empty": {
+			input:    "",
+			expected: nil,
+		},
+		"single statement": {
+			input: `CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+			},
+		},
+		"multiple statements": {
+			input: `
+				CREATE USER {{username}} IDENTIFIED BY "{{password}}";
+				GRANT CONNECT TO {{username}};
+				GRANT CREATE SESSION TO {{username}};`,
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+				`GRANT CONNECT TO {{username}}`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+		},
+		"semicolon in string literal": {
+			input: `INSERT INTO audit_log VALUES ('created; {{username}}');GRANT CONNECT TO {{username}}`,
+			expected: []string{
+				`INSERT INTO audit_log VALUES ('created; {{username}}')`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+		},
+		"define commands": {
+			input: `
+				DEFINE container = PDB1
+				DEFINE tablespace = 'USERS'
+				CREATE USER {{username}} IDENTIFIED BY "{{password}}" DEFAULT TABLESPACE {{tablespace}};
+				GRANT CONNECT TO {{username}};`,
+			expected: []string{
+				`DEFINE container = PDB1`,
+				`DEFINE tablespace = 'USERS'`,
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}" DEFAULT TABLESPACE {{tablespace}}`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+		},
+		"escaped quote in string literal": {
+			input: `INSERT INTO t VALUES ('it''s; fine');SELECT 1 FROM dual`,
+			expected: []string{
+				`INSERT INTO t VALUES ('it''s; fine')`,
+				`SELECT 1 FROM dual`,
+			},
+		},
+		"semicolon in quoted identifier": {
+			input: `ALTER USER "{{username}}" IDENTIFIED BY "pa;ss";DROP USER "a;b"`,
+			expected: []string{
+				`ALTER USER "{{username}}" IDENTIFIED BY "pa;ss"`,
+				`DROP USER "a;b"`,
+			},
+		},
+		"alternative quoting": {
+			input: `INSERT INTO t VALUES (q'[it's; here]');INSERT INTO t VALUES (Q'{a;b}'); INSERT INTO t VALUES (nq'!x;y!')`,
+			expected: []string{
+				`INSERT INTO t VALUES (q'[it's; here]')`,
+				`INSERT INTO t VALUES (Q'{a;b}')`,
+				`INSERT INTO t VALUES (nq'!x;y!')`,
+			},
+		},
+		"identifier ending in q is not alternative quoting": {
+			input: `SELECT seq'a;b' FROM dual`,
+			expected: []string{
+				`SELECT seq'a;b' FROM dual`,
+			},
+		},
+		"comments": {
+			input: `-- create the user; then grant
+				CREATE USER {{username}} IDENTIFIED BY "{{password}}"; /* grant; */ GRANT CONNECT TO {{username}};
+				-- trailing comment;`,
+			expected: []string{
+				"-- create the user; then grant\n\t\t\t\tCREATE USER {{username}} IDENTIFIED BY \"{{password}}\"",
+				`/* grant; */ GRANT CONNECT TO {{username}}`,
+			},
+		},
+		"anonymous block": {
+			input: `BEGIN
+				EXECUTE IMMEDIATE 'GRANT CONNECT TO {{username}}';
+				EXECUTE IMMEDIATE 'GRANT CREATE SESSION TO {{username}}';
+			END;`,
+			expected: []string{
+				`BEGIN
+				EXECUTE IMMEDIATE 'GRANT CONNECT TO {{username}}';
+				EXECUTE IMMEDIATE 'GRANT CREATE SESSION TO {{username}}';
+			END;`,
+			},
+		},
+		"mixed DDL and anonymous blocks": {
+			input: `CREATE USER {{username}} IDENTIFIED BY "{{password}}";
+			declare
+				n number;
+			begin
+				select count(*) into n from dba_roles where role = 'APP_ROLE';
+				if n > 0 then
+					execute immediate 'grant app_role to {{username}}';
+				end if;
+			end;
+			GRANT CREATE SESSION TO {{username}};`,
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+				`declare
+				n number;
+			begin
+				select count(*) into n from dba_roles where role = 'APP_ROLE';
+				if n > 0 then
+					execute immediate 'grant app_role to {{username}}';
+				end if;
+			end;`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+		},
+		"nested blocks, loops and case": {
+			input: `BEGIN
+				FOR x IN (SELECT sid FROM v$session) LOOP
+					BEGIN
+						NULL;
+					EXCEPTION
+						WHEN OTHERS THEN NULL;
+					END;
+				END LOOP;
+				CASE WHEN 1 = 1 THEN NULL; ELSE NULL; END CASE;
+				x := CASE WHEN 1 = 1 THEN 'a' END;
+			END my_block;
+			DROP USER {{username}}`,
+			expected: []string{
+				`BEGIN
+				FOR x IN (SELECT sid FROM v$session) LOOP
+					BEGIN
+						NULL;
+					EXCEPTION
+						WHEN OTHERS THEN NULL;
+					END;
+				END LOOP;
+				CASE WHEN 1 = 1 THEN NULL; ELSE NULL; END CASE;
+				x := CASE WHEN 1 = 1 THEN 'a' END;
+			END my_block;`,
+				`DROP USER {{username}}`,
+			},
+		},
+		"local subprograms": {
+			input: `DECLARE
+				PROCEDURE forward_declared;
+				FUNCTION f(a IN NUMBER) RETURN NUMBER IS
+				BEGIN
+					RETURN a;
+				END f;
+				PROCEDURE forward_declared AS
+				BEGIN
+					NULL;
+				END;
+			BEGIN
+				forward_declared;
+			END;
+			SELECT 1 FROM dual`,
+			expected: []string{
+				`DECLARE
+				PROCEDURE forward_declared;
+				FUNCTION f(a IN NUMBER) RETURN NUMBER IS
+				BEGIN
+					RETURN a;
+				END f;
+				PROCEDURE forward_declared AS
+				BEGIN
+					NULL;
+				END;
+			BEGIN
+				forward_declared;
+			END;`,
+				`SELECT 1 FROM dual`,
+			},
+		},
+		"stored units end at slash": {
+			input: `CREATE OR REPLACE PROCEDURE grant_app(name IN VARCHAR2) AS
+BEGIN
+	EXECUTE IMMEDIATE 'GRANT app_role TO ' || name;
+END;
+/
+CREATE OR REPLACE EDITIONABLE TRIGGER t BEFORE INSERT ON x FOR EACH ROW
+BEGIN
+	:new.id := 1;
+END;
+  /
+BEGIN grant_app('{{username}}'); END;
+/
+GRANT CONNECT TO {{username}}`,
+			expected: []string{
+				`CREATE OR REPLACE PROCEDURE grant_app(name IN VARCHAR2) AS
+BEGIN
+	EXECUTE IMMEDIATE 'GRANT app_role TO ' || name;
+END;`,
+				`CREATE OR REPLACE EDITIONABLE TRIGGER t BEFORE INSERT ON x FOR EACH ROW
+BEGIN
+	:new.id := 1;
+END;`,
+				`BEGIN grant_app('{{username}}'); END;`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+		},
+		"package spec and body": {
+			input: `CREATE PACKAGE p AS
+	PROCEDURE a;
+END p;
+/
+CREATE PACKAGE BODY p AS
+	PROCEDURE a IS BEGIN NULL; END;
+END p;`,
+			expected: []string{
+				`CREATE PACKAGE p AS
+	PROCEDURE a;
+END p;`,
+				`CREATE PACKAGE BODY p AS
+	PROCEDURE a IS BEGIN NULL; END;
+END p;`,
+			},
+		},
+		"create statements that are not stored units": {
+			input: `CREATE USER {{username}} IDENTIFIED BY "{{password}}";CREATE OR REPLACE VIEW v AS SELECT 1 a FROM dual;CREATE ROLE r`,
+			expected: []string{
+				`CREATE USER {{username}} IDENTIFIED BY "{{password}}"`,
+				`CREATE OR REPLACE VIEW v AS SELECT 1 a FROM dual`,
+				`CREATE ROLE r`,
+			},
+		},
+		"slash terminates plain SQL": {
+			input: "GRANT CONNECT TO {{username}}\n/\nGRANT CREATE SESSION TO {{username}}\n/\n",
+			expected: []string{
+				`GRANT CONNECT TO {{username}}`,
+				`GRANT CREATE SESSION TO {{username}}`,
+			},
+		},
+		"division is not a terminator": {
+			input: "SELECT 4\n/ 2 FROM dual",
+			expected: []string{
+				"SELECT 4\n/ 2 FROM dual",
+			},
+		},
+		"slash in comment or string is not a terminator": {
+			input: "BEGIN\n  x := '\n/\n';\n  /*\n/\n*/\n  NULL;\nEND;",
+			expected: []string{
+				"BEGIN\n  x := '\n/\n';\n  /*\n/\n*/\n  NULL;\nEND;",
+			},
+		},
+		"unterminated block": {
+			input: `BEGIN NULL;`,
+			expected: []string{
+				`BEGIN NULL;`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := splitStatements(test.input)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractDefines(t *testing.T) {
+	type testCase struct {
+		input              []string
+		expectedDefines    map[string]string
+		expectedStatements []string
+		expectErr          bool
+	}
+
+	tests := map[string]testCase{
+		"no defines": {
+			input:              []string{`GRANT CONNECT TO {{username}}`},
+			expectedDefines:    map[string]string{},
+			expectedStatements: []string{`GRANT CONNECT TO {{username}}`},
+		},
+		"defines": {
+			input: []string{
+				`DEFINE container = pdb1`,
+				`define Tablespace='USERS'`,
+				`DEFINE quota = "10M";`,
+				`GRANT CONNECT TO {{username}}`,
+			},
+			expectedDefines: map[string]string{
+				"container":  "pdb1",
+				"tablespace": "USERS",
+				"quota":      "10M",
+			},
+			expectedStatements: []string{`GRANT CONNECT TO {{username}}`},
+		},
+		"define followed by statements without split_statements": {
+			input: []string{"DEFINE note = hello\nREVOKE CONNECT FROM {{name}};\nDROP USER {{name}}"},
+			expectedDefines: map[string]string{
+				"note": "hello",
+			},
+			expectedStatements: []string{"REVOKE CONNECT FROM {{name}};\nDROP USER {{name}}"},
+		},
+		"consecutive defines": {
+			input: []string{"DEFINE container = pdb1;\r\nDEFINE quota = '10M'\nGRANT CONNECT TO {{username}}"},
+			expectedDefines: map[string]string{
+				"container": "pdb1",
+				"quota":     "10M",
+			},
+			expectedStatements: []string{"GRANT CONNECT TO {{username}}"},
+		},
+		"identifier starting with define": {
+			input:              []string{`DEFINED_USERS`},
+			expectedDefines:    map[string]string{},
+			expectedStatements: []string{`DEFINED_USERS`},
+		},
+		"invalid define": {
+			input:     []string{`DEFINE container`},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defines, statements, err := extractDefines(test.input)
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			if !reflect.DeepEqual(defines, test.expectedDefines) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", defines, test.expectedDefines)
+			}
+			if !reflect.DeepEqual(statements, test.expectedStatements) {
+				t.Fatalf("Actual: %#v\nExpected: %#v", statements, test.expectedStatements)
+			}
+		})
+	}
+}
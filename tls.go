@@ -0,0 +1,222 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package oracle
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+)
+
+const (
+	// walletFile is the PEM wallet that the Instant Client reads from the wallet directory.
+	walletFile = "ewallet.pem"
+
+	// walletOption is the go-ora URL option that names the wallet directory. go-ora only reads Oracle wallets,
+	// so the option is removed by tlsDriver, which connects with the TLS configuration of the wallet instead.
+	walletOption = "WALLET"
+)
+
+// walletTLSConfigs holds the TLS configuration of each wallet directory written by the plugin.
+var walletTLSConfigs sync.Map // map[string]*tls.Config
+
+// tlsSettings are the tls_* fields of the config.
+type tlsSettings struct {
+	// ca holds the PEM encoded certificates that the server certificate is verified against, and certificate
+	// and key the optional client certificate and its private key.
+	ca          string
+	certificate string
+	key         string
+
+	// serverDNMatch requires the server certificate to match the host that is connected to.
+	serverDNMatch bool
+}
+
+// tlsWallet is a temporary wallet directory holding the certificates of the TLS settings.
+type tlsWallet struct {
+	dir           string
+	serverDNMatch bool
+}
+
+// parseTLSSettings parses and validates the TLS settings of the config. The second return value reports whether
+// any are set.
+func parseTLSSettings(config map[string]interface{}) (tlsSettings, bool, error) {
+	s := tlsSettings{}
+
+	var err error
+	s.ca, err = strutil.GetString(config, "tls_ca")
+	if err != nil {
+		return tlsSettings{}, false, fmt.Errorf("failed to retrieve tls_ca: %w", err)
+	}
+	s.certificate, err = strutil.GetString(config, "tls_certificate")
+	if err != nil {
+		return tlsSettings{}, false, fmt.Errorf("failed to retrieve tls_certificate: %w", err)
+	}
+	s.key, err = strutil.GetString(config, "tls_key")
+	if err != nil {
+		return tlsSettings{}, false, fmt.Errorf("failed to retrieve tls_key: %w", err)
+	}
+	s.serverDNMatch, err = coerceToBool(config, "tls_server_dn_match", true)
+	if err != nil {
+		return tlsSettings{}, false, fmt.Errorf("failed to parse 'tls_server_dn_match' field: %w", err)
+	}
+
+	if s.ca == "" && s.certificate == "" && s.key == "" {
+		return tlsSettings{}, false, nil
+	}
+	if s.ca == "" {
+		return tlsSettings{}, false, fmt.Errorf("tls_ca is required to connect with TLS")
+	}
+	if (s.certificate == "") != (s.key == "") {
+		return tlsSettings{}, false, fmt.Errorf("tls_certificate and tls_key must be set together")
+	}
+
+	// The wallet is parsed the same way as it is when connecting, so that invalid certificates are reported
+	// by Initialize
+	_, err = s.tlsConfig()
+	if err != nil {
+		return tlsSettings{}, false, err
+	}
+	return s, true, nil
+}
+
+// wallet returns the contents of the PEM wallet: the private key and certificate of the client, if any, followed
+// by the CA certificates.
+func (s tlsSettings) wallet() []byte {
+	var wallet []byte
+	for _, block := range []string{s.key, s.certificate, s.ca} {
+		if block == "" {
+			continue
+		}
+		wallet = append(wallet, block...)
+		if wallet[len(wallet)-1] != '\n' {
+			wallet = append(wallet, '\n')
+		}
+	}
+	return wallet
+}
+
+// tlsConfig returns the TLS configuration used by drivers that don't read the wallet themselves.
+func (s tlsSettings) tlsConfig() (*tls.Config, error) {
+	roots := x509.NewCertPool()
+	count := 0
+	rest := []byte(s.ca)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in tls_ca: %w", err)
+		}
+		roots.AddCert(cert)
+		count++
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("tls_ca contains no PEM encoded certificates")
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    roots,
+	}
+	if s.certificate != "" {
+		cert, err := tls.X509KeyPair([]byte(s.certificate), []byte(s.key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls_certificate or tls_key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if !s.serverDNMatch {
+		// The chain of the server certificate is still verified, only the host name isn't checked
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertificateChain(rawCerts, roots)
+		}
+	}
+	return config, nil
+}
+
+// verifyCertificateChain verifies the certificates presented by the server against the roots, without checking
+// the host name.
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("server presented no certificate")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("invalid server certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// writeWallet writes the wallet of the TLS settings into a new temporary directory that only the plugin can read,
+// and registers its TLS configuration.
+func (s tlsSettings) writeWallet() (*tlsWallet, error) {
+	config, err := s.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The directory is created with 0700 permissions
+	dir, err := os.MkdirTemp("", "vault-plugin-database-oracle-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, walletFile), s.wallet(), 0o600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write wallet: %w", err)
+	}
+
+	walletTLSConfigs.Store(dir, config)
+	return &tlsWallet{
+		dir:           dir,
+		serverDNMatch: s.serverDNMatch,
+	}, nil
+}
+
+// remove deletes the wallet directory and its TLS configuration.
+func (w *tlsWallet) remove() error {
+	if w == nil {
+		return nil
+	}
+	walletTLSConfigs.Delete(w.dir)
+	return os.RemoveAll(w.dir)
+}
+
+// walletTLSConfig returns the TLS configuration of the wallet directory.
+func walletTLSConfig(dir string) (*tls.Config, error) {
+	config, ok := walletTLSConfigs.Load(dir)
+	if !ok {
+		return nil, fmt.Errorf("unknown wallet directory %q", dir)
+	}
+	return config.(*tls.Config), nil
+}